@@ -0,0 +1,125 @@
+package kit
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Accepts returns the type of the given media types the client prefers
+// most according to the quality values of its Accept header, or an empty
+// string if none is acceptable. Ties are broken by the order of the
+// given types. A request without an Accept header accepts any type.
+//
+//	switch kit.Accepts("text/html", "application/json") {
+//	case "text/html":
+//		return kit.Render(users.Index(list))
+//	case "application/json":
+//		return kit.JSON(http.StatusOK, list)
+//	}
+//	return kit.ErrNotAcceptable
+func (kit *Kit) Accepts(mimeTypes ...string) string {
+	header := kit.Request.Header.Values("Accept")
+	if len(header) == 0 {
+		if len(mimeTypes) > 0 {
+			return mimeTypes[0]
+		}
+		return ""
+	}
+	ranges := parseAccept(strings.Join(header, ","))
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, mimeType := range mimeTypes {
+		if q := acceptQuality(ranges, mimeType); q > bestQ {
+			best, bestQ = mimeType, q
+		}
+	}
+	return best
+}
+
+// Accept returns a handler rejecting requests with a body whose
+// Content-Type is not one of the given media types with
+// ErrUnsupportedMediaType before the handler runs. Requests without a
+// body, like most GET and DELETE requests, are passed through.
+//
+//	router.POST("/users", handleCreateUser.Accept("application/json"))
+func (h HandlerFunc) Accept(contentTypes ...string) HandlerFunc {
+	return func(kit *Kit) error {
+		r := kit.Request
+		if r.ContentLength == 0 && len(r.TransferEncoding) == 0 {
+			return h(kit)
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return ErrUnsupportedMediaType.Wrap(err)
+		}
+		for _, contentType := range contentTypes {
+			if strings.EqualFold(mediaType, contentType) {
+				return h(kit)
+			}
+		}
+		return ErrUnsupportedMediaType.Wrap(fmt.Errorf("content type (%s) is not accepted", mediaType))
+	}
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	subType   string
+	q         float64
+}
+
+// parseAccept parses the media ranges of the given Accept header.
+// Malformed quality values are treated as the default of 1.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaType, subType, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaRange)), "/")
+		if len(mediaType) == 0 {
+			continue
+		}
+		r := acceptRange{mediaType: mediaType, subType: strings.TrimSpace(subType), q: 1}
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching
+// the given media type, or 0 if none matches.
+func acceptQuality(ranges []acceptRange, mimeType string) float64 {
+	mediaType, subType, _ := strings.Cut(strings.ToLower(mimeType), "/")
+	var (
+		q           float64
+		specificity = -1
+	)
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.mediaType == mediaType && r.subType == subType:
+			s = 2
+		case r.mediaType == mediaType && r.subType == "*":
+			s = 1
+		case r.mediaType == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,53 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccepts(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/html;q=0.8, application/json, text/*;q=0.5, */*;q=0.1")
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "application/json", kit.Accepts("text/html", "application/json"))
+	assert.Equal(t, "text/html", kit.Accepts("text/plain", "text/html"))
+	assert.Equal(t, "text/plain", kit.Accepts("text/plain", "image/png"))
+	assert.Equal(t, "image/png", kit.Accepts("image/png"))
+}
+
+func TestAcceptsNoMatch(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json, text/html;q=0")
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "", kit.Accepts("text/html", "image/png"))
+	assert.Equal(t, "", kit.Accepts())
+}
+
+func TestAcceptsMissingHeader(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "text/html", kit.Accepts("text/html", "application/json"))
+}
+
+func TestHandlerFuncAccept(t *testing.T) {
+	h := Handler(HandlerFunc(func(kit *Kit) error {
+		return kit.Text(http.StatusOK, "ok")
+	}).Accept("application/json", "application/x-www-form-urlencoded"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newJSONRequest(`{"email": "foo@bar.com"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("<user/>"))
+	r.Header.Set("Content-Type", "application/xml")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
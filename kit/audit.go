@@ -0,0 +1,58 @@
+package kit
+
+import (
+	"log/slog"
+)
+
+// LevelAudit is the level of the records emitted by Kit.Audit. It is
+// above slog.LevelError, so audit records are never filtered out by
+// the level of a handler.
+const LevelAudit = slog.Level(12)
+
+// ReplaceAuditLevel names LevelAudit AUDIT in the output of the slog
+// handlers, which would print it as ERROR+4 otherwise. Use it as the
+// ReplaceAttr of the handler options of the audit logger.
+//
+//	kit.SetAuditLogger(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+//		ReplaceAttr: kit.ReplaceAuditLevel,
+//	})))
+func ReplaceAuditLevel(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.LevelKey {
+		return a
+	}
+	if level, ok := a.Value.Any().(slog.Level); ok && level == LevelAudit {
+		a.Value = slog.StringValue("AUDIT")
+	}
+	return a
+}
+
+var auditLogger *slog.Logger
+
+// SetAuditLogger sets the logger used by Kit.Audit, which allows routing
+// the audit trail to a separate sink. Defaults to the logger set with
+// SetLogger. See ReplaceAuditLevel for naming the level of the records.
+func SetAuditLogger(logger *slog.Logger) { auditLogger = logger }
+
+// Audit emits an audit record for the given action, including the ID of
+// the authenticated user, the client IP, the request ID and the given
+// details.
+//
+//	kit.Audit("user.delete", map[string]any{"deleted_user_id": id})
+func (kit *Kit) Audit(action string, details map[string]any) {
+	logger := auditLogger
+	if logger == nil {
+		logger = defaultLogger()
+	}
+	userID, _ := kit.UserID()
+	attrs := make([]any, 0, len(details))
+	for key, value := range details {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	logger.Log(kit.Request.Context(), LevelAudit, "audit",
+		"action", action,
+		"user_id", userID,
+		"client_ip", kit.ClientIP(),
+		"request_id", kit.RequestID(),
+		slog.Group("details", attrs...),
+	)
+}
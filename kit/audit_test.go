@@ -0,0 +1,93 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthdm/superkit/kit/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetAuditLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: ReplaceAuditLevel,
+	})))
+	defer SetAuditLogger(nil)
+
+	h := WithRequestID(Handler(func(kit *Kit) error {
+		kit.Audit("user.delete", map[string]any{"deleted_user_id": 7})
+		return nil
+	}))
+	r := httptest.NewRequest("DELETE", "/users/7", nil)
+	r.RemoteAddr = "1.2.3.4:1234"
+	r = r.WithContext(WithAuth(r.Context(), testIdentity{id: "42"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Contains(t, buf.String(), `"level":"AUDIT"`)
+	record := map[string]any{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "AUDIT", record["level"])
+	assert.Equal(t, "audit", record["msg"])
+	assert.Equal(t, "user.delete", record["action"])
+	assert.Equal(t, "42", record["user_id"])
+	assert.Equal(t, "1.2.3.4", record["client_ip"])
+	assert.Equal(t, w.Header().Get(RequestIDHeader), record["request_id"])
+	assert.NotEmpty(t, record["request_id"])
+	assert.Equal(t, map[string]any{"deleted_user_id": float64(7)}, record["details"])
+}
+
+func TestWithRequestID(t *testing.T) {
+	var id string
+	h := WithRequestID(Handler(func(kit *Kit) error {
+		id = kit.RequestID()
+		return nil
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(RequestIDHeader, "client-id")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "client-id", id)
+	assert.Equal(t, "client-id", w.Header().Get(RequestIDHeader))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Len(t, id, 32)
+}
+
+func TestReplaceAuditLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: ReplaceAuditLevel,
+	}))
+	logger.Log(context.Background(), LevelAudit, "audit")
+	logger.Error("failed")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "level=AUDIT msg=audit")
+	assert.Contains(t, lines[1], "level=ERROR msg=failed")
+}
+
+func TestWithRequestIDResponseCache(t *testing.T) {
+	keyFn := func(r *http.Request) (string, bool) { return r.URL.String(), true }
+	cache := middleware.WithResponseCache(middleware.NewMemoryResponseStore(), time.Minute, keyFn)
+	h := WithRequestID(cache(Handler(func(kit *Kit) error {
+		return kit.Text(http.StatusOK, "posts")
+	})))
+
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, httptest.NewRequest("GET", "/posts", nil))
+	second := httptest.NewRecorder()
+	h.ServeHTTP(second, httptest.NewRequest("GET", "/posts", nil))
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, "posts", second.Body.String())
+	assert.NotEmpty(t, second.Header().Get(RequestIDHeader))
+	assert.NotEqual(t, first.Header().Get(RequestIDHeader), second.Header().Get(RequestIDHeader))
+}
@@ -0,0 +1,208 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCountingRecorder counts the calls to WriteHeader.
+type writeCountingRecorder struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *writeCountingRecorder) WriteHeader(status int) {
+	w.writes++
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func TestWithAuthenticationHandled(t *testing.T) {
+	config := AuthenticationConfig{
+		AuthFunc: func(kit *Kit) (Auth, error) {
+			http.Redirect(kit.Response, kit.Request, "https://oauth.example.com", http.StatusFound)
+			return nil, ErrAuthHandled
+		},
+		RedirectURL: "/login",
+	}
+	called := false
+	h := WithAuthentication(config, true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	w := &writeCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/profile", nil))
+	assert.False(t, called)
+	assert.Equal(t, 1, w.writes)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://oauth.example.com", w.Header().Get("Location"))
+}
+
+func TestWithAuthenticationAPIError(t *testing.T) {
+	config := AuthenticationConfig{
+		AuthFunc: func(kit *Kit) (Auth, error) {
+			return nil, ErrForbidden
+		},
+	}
+	w := httptest.NewRecorder()
+	WithAuthentication(config, false)(okHandler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func anonymousAuthConfig() AuthenticationConfig {
+	return AuthenticationConfig{
+		AuthFunc: func(kit *Kit) (Auth, error) {
+			return DefaultAuth{}, nil
+		},
+		RedirectURL: "/login",
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	w := httptest.NewRecorder()
+	RequireAuth(anonymousAuthConfig())(okHandler).ServeHTTP(w, httptest.NewRequest("GET", "/profile", nil))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/login", w.Header().Get("Location"))
+}
+
+func TestOptionalAuth(t *testing.T) {
+	var auth Auth
+	h := OptionalAuth(anonymousAuthConfig())(Handler(func(kit *Kit) error {
+		auth = kit.Auth()
+		return kit.Text(http.StatusOK, "ok")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, DefaultAuth{}, auth)
+}
+
+func TestMultiAuth(t *testing.T) {
+	var (
+		sessionAuth = func(kit *Kit) (Auth, error) {
+			return testAuth{}, nil
+		}
+		tokenAuth = func(kit *Kit) (Auth, error) {
+			if kit.Request.Header.Get("Authorization") == "Bearer secret" {
+				return testAuth{ID: 1}, nil
+			}
+			return nil, nil
+		}
+		authFunc = MultiAuth(sessionAuth, tokenAuth)
+	)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	kit, _ := newTestKit(r)
+	auth, err := authFunc(kit)
+	assert.Nil(t, err)
+	assert.Equal(t, testAuth{ID: 1}, auth)
+
+	kit, _ = newTestKit(httptest.NewRequest("GET", "/", nil))
+	auth, err = authFunc(kit)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultAuth{}, auth)
+	assert.False(t, auth.Check())
+}
+
+func TestMultiAuthError(t *testing.T) {
+	called := false
+	authFunc := MultiAuth(func(kit *Kit) (Auth, error) {
+		return nil, ErrForbidden
+	}, func(kit *Kit) (Auth, error) {
+		called = true
+		return testAuth{ID: 1}, nil
+	})
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	_, err := authFunc(kit)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.False(t, called)
+}
+
+func TestRequireAuthCallerTypes(t *testing.T) {
+	config := anonymousAuthConfig()
+	config.APIPrefixes = []string{"/api/"}
+	h := RequireAuth(config)(okHandler)
+
+	// API routes get a JSON 401.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/users", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("Location"))
+
+	// As do fetch calls accepting JSON.
+	r := httptest.NewRequest("GET", "/profile", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// HTMX requests get a HX-Redirect.
+	r = httptest.NewRequest("GET", "/api/users", nil)
+	r.Header.Set("HX-Request", "true")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "/login", w.Header().Get("HX-Redirect"))
+
+	// Browser navigations are redirected.
+	r = httptest.NewRequest("GET", "/profile", nil)
+	r.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/login", w.Header().Get("Location"))
+}
+
+func TestWithAuthenticationSharesKit(t *testing.T) {
+	config := AuthenticationConfig{
+		AuthFunc: func(kit *Kit) (Auth, error) {
+			kit.Set("tenant", "acme")
+			return testAuth{ID: 1}, nil
+		},
+	}
+	var (
+		tenant any
+		auth   Auth
+	)
+	h := RequireAuth(config)(Handler(func(kit *Kit) error {
+		tenant = kit.Get("tenant")
+		auth = kit.Auth()
+		return nil
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, testAuth{ID: 1}, auth)
+}
+
+func TestOptionalAuthAnonymousNoWarning(t *testing.T) {
+	logger, buf := newTestLogger()
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	var (
+		auth    Auth
+		hasAuth bool
+	)
+	h := OptionalAuth(anonymousAuthConfig())(Handler(func(kit *Kit) error {
+		auth = kit.Auth()
+		hasAuth = kit.HasAuth()
+		return nil
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, DefaultAuth{}, auth)
+	assert.True(t, hasAuth)
+	assert.Empty(t, buf.String())
+}
+
+func TestAuthWithoutMiddlewareWarns(t *testing.T) {
+	logger, buf := newTestLogger()
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, DefaultAuth{}, kit.Auth())
+	assert.False(t, kit.HasAuth())
+	assert.Contains(t, buf.String(), "kit authentication not set")
+}
@@ -0,0 +1,537 @@
+package kit
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anthdm/superkit/validate"
+)
+
+// BindAll binds the request into a value of type T, drawing values from
+// the JSON body (`json:"..."`), the query parameters (`query:"..."`) and
+// the path parameters (`path:"..."`). When a field is present in multiple
+// sources path parameters take precedence over query parameters, which in
+// turn take precedence over the JSON body. A malformed body or a value
+// that fails to parse results in ErrBadRequest.
+//
+//	type UpdateUserRequest struct {
+//		ID    int    `path:"id"`
+//		Email string `json:"email"`
+//	}
+//	req, err := kit.BindAll[UpdateUserRequest](k)
+func BindAll[T any](kit *Kit) (T, error) {
+	var v T
+	body, err := kit.body()
+	if err != nil {
+		return v, bodyError(err)
+	}
+	if err := decodeJSON(body, &v); err != nil && !errors.Is(err, io.EOF) {
+		return v, bodyError(err)
+	}
+	query := kit.Request.URL.Query()
+	err = bindTagged(&v, "query", func(name string) []string {
+		return query[name]
+	})
+	if err != nil {
+		return v, ErrBadRequest.Wrap(err)
+	}
+	err = bindTagged(&v, "path", func(name string) []string {
+		if value := kit.Request.PathValue(name); len(value) > 0 {
+			return []string{value}
+		}
+		return nil
+	})
+	if err != nil {
+		return v, ErrBadRequest.Wrap(err)
+	}
+	return v, nil
+}
+
+// Bind decodes the JSON body of the request into a value of type T. A
+// malformed body results in ErrBadRequest wrapping a BindError.
+//
+//	user, err := kit.Bind[CreateUserRequest](k)
+func Bind[T any](kit *Kit) (T, error) {
+	var v T
+	return v, kit.decodeJSONBody(&v)
+}
+
+// MustBind is like Bind, but panics with the *APIError, ErrBadRequest for
+// a malformed body, instead of returning it. It must be used behind
+// WithRecovery, which responds with the status of the error.
+//
+//	user := kit.MustBind[CreateUserRequest](k)
+func MustBind[T any](kit *Kit) T {
+	v, err := Bind[T](kit)
+	if err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			apiErr = ErrBadRequest.Wrap(err)
+		}
+		panic(apiErr)
+	}
+	return v
+}
+
+// BindPatch applies the JSON body of the request as a JSON merge patch
+// (RFC 7386) onto existing, returning the merged value. Only the fields
+// present in the patch are overwritten, fields explicitly set to null are
+// reset to their zero value. Fields hidden from JSON, like unexported or
+// `json:"-"` fields, keep their existing value. Existing is never
+// modified.
+//
+//	user, err = kit.BindPatch(k, user)
+func BindPatch[T any](kit *Kit, existing T) (T, error) {
+	var raw json.RawMessage
+	if err := kit.decodeJSONBody(&raw); err != nil {
+		return existing, err
+	}
+	var patch map[string]any
+	if err := decodeJSONNumbers(raw, &patch); err != nil || patch == nil {
+		return existing, ErrBadRequest.Wrap(&BindError{Message: "merge patch must be a json object"})
+	}
+	b, err := json.Marshal(existing)
+	if err != nil {
+		return existing, err
+	}
+	var doc map[string]any
+	if err := decodeJSONNumbers(b, &doc); err != nil {
+		return existing, err
+	}
+	if b, err = json.Marshal(mergePatch(doc, patch)); err != nil {
+		return existing, err
+	}
+	v := patchTarget(existing)
+	if err := decodeJSON(bytes.NewReader(b), &v); err != nil {
+		return existing, ErrBadRequest.Wrap(err)
+	}
+	return v, nil
+}
+
+// decodeJSONNumbers decodes b into v keeping numbers as json.Number, since
+// large integers would lose precision as float64.
+func decodeJSONNumbers(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// patchTarget returns a copy of existing the merged document is decoded
+// into. The fields visible to JSON are reset, since the document holds
+// all of them, while the hidden fields are kept. Values which are not
+// structs are decoded from scratch.
+func patchTarget[T any](existing T) T {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	src := reflect.ValueOf(existing)
+	switch {
+	case rv.Kind() == reflect.Struct:
+		rv.Set(src)
+		resetJSONFields(rv)
+	case rv.Kind() == reflect.Pointer && rv.Type().Elem().Kind() == reflect.Struct && !src.IsNil():
+		// Copy the struct, so existing is not modified through the pointer.
+		rv.Set(reflect.New(rv.Type().Elem()))
+		rv.Elem().Set(src.Elem())
+		resetJSONFields(rv.Elem())
+	}
+	return v
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// resetJSONFields resets the fields of the given struct which are visible
+// to JSON, recursing into nested structs so their hidden fields are kept.
+func resetJSONFields(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if !fv.CanSet() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		if fv.Kind() == reflect.Struct && !reflect.PointerTo(fv.Type()).Implements(jsonUnmarshalerType) {
+			resetJSONFields(fv)
+			continue
+		}
+		fv.SetZero()
+	}
+}
+
+// mergePatch applies the merge patch onto the given document.
+func mergePatch(doc, patch map[string]any) map[string]any {
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		if p, ok := value.(map[string]any); ok {
+			d, _ := doc[key].(map[string]any)
+			doc[key] = mergePatch(d, p)
+			continue
+		}
+		doc[key] = value
+	}
+	return doc
+}
+
+// ErrEmptyBody is wrapped by the errors of the binding helpers when the
+// request has no body.
+var ErrEmptyBody = errors.New("empty body")
+
+// BindJSON decodes the JSON body of the request into dst, which must be a
+// pointer. Unlike Bind the Content-Type is checked, if present it must be
+// JSON or ErrUnsupportedMediaType is returned. An empty body results in
+// ErrBadRequest wrapping ErrEmptyBody, a malformed body in ErrBadRequest
+// wrapping a BindError.
+//
+//	var req CreateUserRequest
+//	if err := kit.BindJSON(&req); err != nil {
+//		return err
+//	}
+func (kit *Kit) BindJSON(dst any) error {
+	if contentType := kit.Request.Header.Get("Content-Type"); len(contentType) > 0 {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return ErrUnsupportedMediaType.Wrap(err)
+		}
+		if !isJSONMediaType(mediaType) {
+			return ErrUnsupportedMediaType.Wrap(fmt.Errorf("content type (%s) is not json", mediaType))
+		}
+	}
+	return kit.decodeJSONBody(dst)
+}
+
+// decodeJSONBody decodes the JSON body of the request into v.
+func (kit *Kit) decodeJSONBody(v any) error {
+	body, err := kit.body()
+	if err != nil {
+		return bodyError(err)
+	}
+	if err := decodeJSON(body, v); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = &BindError{Message: ErrEmptyBody.Error(), Err: ErrEmptyBody}
+		}
+		return bodyError(err)
+	}
+	return nil
+}
+
+// bodyError wraps an error reading or decoding the request body in
+// ErrBadRequest. Timeouts are kept as is and bodies exceeding the limit
+// of a http.MaxBytesReader result in ErrRequestEntityTooLarge.
+func bodyError(err error) error {
+	if errors.Is(err, ErrRequestTimeout) {
+		return err
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return ErrRequestEntityTooLarge.Wrap(err)
+	}
+	return ErrBadRequest.Wrap(err)
+}
+
+// BindValidate is like Bind, after which the value is validated based on
+// its `validate:"..."` tags. Failing validation results in
+// ErrUnprocessableEntity wrapping a ValidationError.
+func BindValidate[T any](kit *Kit) (T, error) {
+	v, err := Bind[T](kit)
+	if err != nil {
+		return v, err
+	}
+	return v, validateStruct(v)
+}
+
+// BindAuto binds the request body into a value of type T based on the
+// Content-Type of the request. JSON bodies are decoded like Bind, form
+// bodies are bound based on the `form:"..."` tags and XML bodies are
+// decoded with encoding/xml. Other content types result in
+// ErrUnsupportedMediaType.
+func BindAuto[T any](kit *Kit) (T, error) {
+	var v T
+	mediaType, _, err := mime.ParseMediaType(kit.Request.Header.Get("Content-Type"))
+	if err != nil {
+		return v, ErrUnsupportedMediaType.Wrap(err)
+	}
+	switch {
+	case isJSONMediaType(mediaType):
+		return Bind[T](kit)
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return BindForm[T](kit)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		body, err := kit.body()
+		if err != nil {
+			return v, bodyError(err)
+		}
+		if err := xml.NewDecoder(body).Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = &BindError{Message: ErrEmptyBody.Error(), Err: ErrEmptyBody}
+			}
+			return v, bodyError(err)
+		}
+		return v, nil
+	}
+	return v, ErrUnsupportedMediaType.Wrap(fmt.Errorf("content type (%s) is not supported", mediaType))
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Value returns the value of the given parameter, looked up in the form
+// body, the query parameters and the top-level fields of a JSON body, in
+// that order. JSON strings are returned unquoted, other JSON values as
+// is. An empty string is returned when the parameter is absent.
+//
+//	email := kit.Value("email")
+func (kit *Kit) Value(key string) string {
+	if values := kit.FormValues(key); len(values) > 0 {
+		return values[0]
+	}
+	if query := kit.Request.URL.Query(); query.Has(key) {
+		return query.Get(key)
+	}
+	raw, ok := kit.jsonFields()[key]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+// jsonFields returns the top-level fields of a JSON body, decoding the
+// cached body once. Non JSON bodies have no fields.
+func (kit *Kit) jsonFields() map[string]json.RawMessage {
+	if kit.jsonValues != nil {
+		return kit.jsonValues
+	}
+	kit.jsonValues = map[string]json.RawMessage{}
+	mediaType, _, _ := mime.ParseMediaType(kit.Request.Header.Get("Content-Type"))
+	if !isJSONMediaType(mediaType) {
+		return kit.jsonValues
+	}
+	b, err := kit.RawBody()
+	if err != nil {
+		return kit.jsonValues
+	}
+	json.Unmarshal(b, &kit.jsonValues)
+	return kit.jsonValues
+}
+
+// BindForm binds the form values of the request, both url encoded and
+// multipart, into a value of type T based on its `form:"..."` tags.
+func BindForm[T any](kit *Kit) (T, error) {
+	var v T
+	if err := kit.parseForm(); err != nil {
+		return v, bodyError(err)
+	}
+	err := bindTagged(&v, "form", func(name string) []string {
+		return kit.Request.Form[name]
+	})
+	if err != nil {
+		return v, ErrBadRequest.Wrap(err)
+	}
+	return v, nil
+}
+
+// parseForm parses the form of the request, including multipart bodies.
+func (kit *Kit) parseForm() error {
+	// ParseMultipartForm hides the errors reading url encoded bodies
+	// behind http.ErrNotMultipart.
+	if err := kit.Request.ParseForm(); err != nil {
+		return err
+	}
+	err := kit.parseMultipartForm()
+	if errors.Is(err, http.ErrNotMultipart) {
+		return nil
+	}
+	return err
+}
+
+// BindError describes why a request body failed to decode, including
+// the offending field and the byte offset in the body, if known.
+type BindError struct {
+	Field   string
+	Offset  int64
+	Message string
+	Err     error
+}
+
+func (e *BindError) Error() string {
+	switch {
+	case len(e.Field) > 0:
+		return fmt.Sprintf("invalid value for field %s at offset %d: %s", e.Field, e.Offset, e.Message)
+	case e.Offset > 0:
+		return fmt.Sprintf("invalid json at offset %d: %s", e.Offset, e.Message)
+	}
+	return e.Message
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// decodeJSON decodes the given JSON into v, converting syntax and type
+// errors into a BindError. An empty input results in io.EOF.
+func decodeJSON(r io.Reader, v any) error {
+	err := json.NewDecoder(r).Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &BindError{Offset: syntaxErr.Offset, Message: syntaxErr.Error(), Err: err}
+	case errors.As(err, &typeErr):
+		return &BindError{
+			Field:   typeErr.Field,
+			Offset:  typeErr.Offset,
+			Message: fmt.Sprintf("expected %s got %s", typeErr.Type, typeErr.Value),
+			Err:     err,
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &BindError{Message: "unexpected end of json", Err: err}
+	}
+	return &BindError{Message: err.Error(), Err: err}
+}
+
+// BindQuery binds the query parameters into a value of type T based on
+// its `query:"..."` tags, after which the value is validated based on its
+// `validate:"..."` tags. Slice fields receive all the values of repeated
+// parameters. A parameter that fails to parse results in ErrBadRequest,
+// failing validation in ErrUnprocessableEntity wrapping a ValidationError.
+//
+//	type SearchRequest struct {
+//		Query string   `query:"q" validate:"required,min=3"`
+//		Tags  []string `query:"tag"`
+//	}
+//	req, err := kit.BindQuery[SearchRequest](k)
+func BindQuery[T any](kit *Kit) (T, error) {
+	var v T
+	query := kit.Request.URL.Query()
+	err := bindTagged(&v, "query", func(name string) []string {
+		return query[name]
+	})
+	if err != nil {
+		return v, ErrBadRequest.Wrap(err)
+	}
+	return v, validateStruct(v)
+}
+
+// ValidationError is the error returned by the binding helpers when the
+// bound value fails validation.
+type ValidationError struct {
+	Errors validate.Errors
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for field, msgs := range e.Errors {
+		fields = append(fields, fmt.Sprintf("%s %s", field, strings.Join(msgs, ", ")))
+	}
+	sort.Strings(fields)
+	return "validation failed: " + strings.Join(fields, "; ")
+}
+
+func validateStruct(v any) error {
+	errs, ok := validate.Struct(v)
+	if !ok {
+		return ErrUnprocessableEntity.Wrap(&ValidationError{Errors: errs})
+	}
+	return nil
+}
+
+// bindTagged sets the fields of the struct v points to which are tagged
+// with the given tag, using the values returned by lookup.
+func bindTagged(v any, tag string, lookup func(name string) []string) error {
+	val := reflect.ValueOf(v).Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a struct got %s", val.Kind())
+	}
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		name := field.Tag.Get(tag)
+		if len(name) == 0 || name == "-" || !field.IsExported() {
+			continue
+		}
+		values := lookup(name)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setFieldValues(val.Field(i), values); err != nil {
+			return fmt.Errorf("failed to bind %s %q: %w", tag, name, err)
+		}
+	}
+	return nil
+}
+
+// setFieldValues sets the given field from its string representation.
+// Slice fields receive all the values, other fields the first one.
+func setFieldValues(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFieldValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setFieldValue(field, values[0])
+}
+
+func setFieldValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse bool: %v", err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("failed to parse int: %v", err)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("failed to parse uint: %v", err)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("failed to parse float: %v", err)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported kind %s", field.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anthdm/superkit/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindAll(t *testing.T) {
+	type UpdateUserRequest struct {
+		ID     int    `path:"id" json:"id"`
+		Notify bool   `query:"notify"`
+		Email  string `json:"email"`
+		Name   string `json:"name"`
+	}
+	var (
+		req UpdateUserRequest
+		err error
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /users/{id}", Handler(func(kit *Kit) error {
+		req, err = BindAll[UpdateUserRequest](kit)
+		return err
+	}))
+	body := strings.NewReader(`{"id": 99, "email": "foo@bar.com", "name": "foo"}`)
+	r := httptest.NewRequest("PUT", "/users/1?notify=true", body)
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, UpdateUserRequest{
+		ID:     1,
+		Notify: true,
+		Email:  "foo@bar.com",
+		Name:   "foo",
+	}, req)
+}
+
+func TestBindAllInvalidPathParam(t *testing.T) {
+	type Request struct {
+		ID int `path:"id"`
+	}
+	var err error
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", Handler(func(kit *Kit) error {
+		_, err = BindAll[Request](kit)
+		return nil
+	}))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/users/foo", nil))
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestBindAllInvalidQueryParam(t *testing.T) {
+	type Request struct {
+		Page int `query:"page"`
+	}
+	h := Handler(func(kit *Kit) error {
+		_, err := BindAll[Request](kit)
+		return err
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/users?page=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+type searchRequest struct {
+	Query string   `query:"q" validate:"required,min=3"`
+	Tags  []string `query:"tag"`
+	Page  int      `query:"page"`
+}
+
+func TestBindQuery(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/?q=shoes&tag=red&tag=blue&page=2", nil))
+	req, err := BindQuery[searchRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, searchRequest{
+		Query: "shoes",
+		Tags:  []string{"red", "blue"},
+		Page:  2,
+	}, req)
+}
+
+func TestBindQueryValidation(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/?tag=red", nil))
+	_, err := BindQuery[searchRequest](kit)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+	var verr *ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.Contains(t, verr.Errors["query"], "is a required field")
+
+	kit, _ = newTestKit(httptest.NewRequest("GET", "/?q=sh", nil))
+	_, err = BindQuery[searchRequest](kit)
+	assert.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Errors["query"], 1)
+}
+
+func TestBindQueryInvalidParam(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/?q=shoes&page=two", nil))
+	_, err := BindQuery[searchRequest](kit)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+type createUserRequest struct {
+	Email string `json:"email" validate:"email"`
+	Age   int    `json:"age"`
+}
+
+func newJSONRequest(body string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+func TestBind(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com", "age": 30}`))
+	user, err := Bind[createUserRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, createUserRequest{Email: "foo@bar.com", Age: 30}, user)
+}
+
+func TestBindTypeMismatch(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com", "age": "x"}`))
+	_, err := Bind[createUserRequest](kit)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	var bindErr *BindError
+	assert.ErrorAs(t, err, &bindErr)
+	assert.Equal(t, "age", bindErr.Field)
+	assert.Equal(t, int64(35), bindErr.Offset)
+}
+
+func TestBindSyntaxError(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com",, "age": 1}`))
+	_, err := Bind[createUserRequest](kit)
+	var bindErr *BindError
+	assert.ErrorAs(t, err, &bindErr)
+	assert.Empty(t, bindErr.Field)
+	assert.Equal(t, int64(25), bindErr.Offset)
+	assert.Contains(t, err.Error(), "offset 25")
+
+	kit, _ = newTestKit(newJSONRequest(""))
+	_, err = Bind[createUserRequest](kit)
+	assert.ErrorAs(t, err, &bindErr)
+	assert.Equal(t, "empty body", bindErr.Message)
+}
+
+func TestBindErrorResponse(t *testing.T) {
+	t.Setenv("SUPERKIT_ENV", "development")
+	w := httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		_, err := Bind[createUserRequest](kit)
+		return err
+	}).ServeHTTP(w, newJSONRequest(`{"age": "x"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid value for field age")
+}
+
+func TestBindValidate(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo"}`))
+	_, err := BindValidate[createUserRequest](kit)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+
+	kit, _ = newTestKit(newJSONRequest(`{"email": "foo@bar.com"}`))
+	_, err = BindValidate[createUserRequest](kit)
+	assert.Nil(t, err)
+}
+
+type bindAutoRequest struct {
+	Email string `json:"email" form:"email" xml:"email"`
+	Age   int    `json:"age" form:"age" xml:"age"`
+}
+
+func TestBindAutoJSON(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com", "age": 30}`))
+	req, err := BindAuto[bindAutoRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, bindAutoRequest{Email: "foo@bar.com", Age: 30}, req)
+}
+
+func TestBindAutoForm(t *testing.T) {
+	kit, _ := newTestKit(newFormRequest("email=foo@bar.com&age=30"))
+	req, err := BindAuto[bindAutoRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, bindAutoRequest{Email: "foo@bar.com", Age: 30}, req)
+}
+
+func TestBindAutoMultipart(t *testing.T) {
+	type uploadRequest struct {
+		Title string `form:"title"`
+	}
+	kit, _ := newTestKit(newUploadRequest(t, "file", "foo.txt", []byte("foo")))
+	req, err := BindAuto[uploadRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, "my upload", req.Title)
+}
+
+func TestBindAutoXML(t *testing.T) {
+	body := `<request><email>foo@bar.com</email><age>30</age></request>`
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	kit, _ := newTestKit(r)
+	req, err := BindAuto[bindAutoRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, bindAutoRequest{Email: "foo@bar.com", Age: 30}, req)
+}
+
+func TestBindAutoUnsupportedMediaType(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader("foo"))
+	r.Header.Set("Content-Type", "text/plain")
+	kit, _ := newTestKit(r)
+	_, err := BindAuto[bindAutoRequest](kit)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+
+	kit, _ = newTestKit(httptest.NewRequest("POST", "/", strings.NewReader("foo")))
+	_, err = BindAuto[bindAutoRequest](kit)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+}
+
+func TestKitBindJSON(t *testing.T) {
+	var user createUserRequest
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com", "age": 30}`))
+	assert.Nil(t, kit.BindJSON(&user))
+	assert.Equal(t, createUserRequest{Email: "foo@bar.com", Age: 30}, user)
+
+	// Requests without a Content-Type are decoded as well.
+	user = createUserRequest{}
+	kit, _ = newTestKit(httptest.NewRequest("POST", "/", strings.NewReader(`{"age": 30}`)))
+	assert.Nil(t, kit.BindJSON(&user))
+	assert.Equal(t, 30, user.Age)
+}
+
+func TestKitBindJSONErrors(t *testing.T) {
+	var user createUserRequest
+	kit, _ := newTestKit(newJSONRequest(""))
+	err := kit.BindJSON(&user)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	assert.ErrorIs(t, err, ErrEmptyBody)
+
+	kit, _ = newTestKit(newJSONRequest(`{"email": `))
+	err = kit.BindJSON(&user)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	assert.NotErrorIs(t, err, ErrEmptyBody)
+	var bindErr *BindError
+	assert.ErrorAs(t, err, &bindErr)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"age": 30}`))
+	r.Header.Set("Content-Type", "text/plain")
+	kit, _ = newTestKit(r)
+	err = kit.BindJSON(&user)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+}
+
+type patchUserRequest struct {
+	Name    string            `json:"name"`
+	Email   string            `json:"email"`
+	Age     *int              `json:"age"`
+	Address map[string]string `json:"address"`
+}
+
+func TestBindPatch(t *testing.T) {
+	age := 30
+	existing := patchUserRequest{
+		Name:    "foo",
+		Email:   "foo@bar.com",
+		Age:     &age,
+		Address: map[string]string{"city": "Ghent", "zip": "9000"},
+	}
+	kit, _ := newTestKit(newJSONRequest(`{"email": "baz@bar.com", "address": {"zip": "9050"}}`))
+	user, err := BindPatch(kit, existing)
+	assert.Nil(t, err)
+	assert.Equal(t, patchUserRequest{
+		Name:    "foo",
+		Email:   "baz@bar.com",
+		Age:     &age,
+		Address: map[string]string{"city": "Ghent", "zip": "9050"},
+	}, user)
+}
+
+func TestBindPatchNull(t *testing.T) {
+	age := 30
+	existing := patchUserRequest{Name: "foo", Age: &age}
+	kit, _ := newTestKit(newJSONRequest(`{"age": null}`))
+	user, err := BindPatch(kit, existing)
+	assert.Nil(t, err)
+	assert.Equal(t, patchUserRequest{Name: "foo"}, user)
+	assert.Equal(t, 30, *existing.Age)
+}
+
+func TestBindPatchHiddenFields(t *testing.T) {
+	type account struct {
+		ID       int64  `json:"id"`
+		Name     string `json:"name"`
+		Password string `json:"-"`
+		Profile  struct {
+			Bio   string `json:"bio"`
+			token string
+		} `json:"profile"`
+		secret string
+	}
+	existing := account{ID: 9007199254740993, Name: "foo", Password: "hash", secret: "s"}
+	existing.Profile.Bio = "hi"
+	existing.Profile.token = "t"
+	kit, _ := newTestKit(newJSONRequest(`{"name": "bar", "profile": {"bio": "hello"}}`))
+	v, err := BindPatch(kit, existing)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9007199254740993), v.ID)
+	assert.Equal(t, "bar", v.Name)
+	assert.Equal(t, "hash", v.Password)
+	assert.Equal(t, "s", v.secret)
+	assert.Equal(t, "hello", v.Profile.Bio)
+	assert.Equal(t, "t", v.Profile.token)
+
+	kit, _ = newTestKit(newJSONRequest(`{"id": 9007199254740995}`))
+	v, err = BindPatch(kit, existing)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9007199254740995), v.ID)
+}
+
+func TestBindPatchKeepsExisting(t *testing.T) {
+	age := 30
+	existing := &patchUserRequest{Age: &age, Address: map[string]string{"zip": "9000"}}
+	kit, _ := newTestKit(newJSONRequest(`{"age": 31, "address": {"zip": "9050"}}`))
+	user, err := BindPatch(kit, existing)
+	assert.Nil(t, err)
+	assert.Equal(t, 31, *user.Age)
+	assert.Equal(t, "9050", user.Address["zip"])
+	assert.Equal(t, 30, *existing.Age)
+	assert.Equal(t, "9000", existing.Address["zip"])
+}
+
+func TestBindPatchInvalid(t *testing.T) {
+	for _, body := range []string{`{"age": "x"}`, `[1]`, `null`, ``} {
+		kit, _ := newTestKit(newJSONRequest(body))
+		_, err := BindPatch(kit, patchUserRequest{Name: "foo"})
+		assert.ErrorIs(t, err, ErrBadRequest, body)
+	}
+}
+
+func TestValue(t *testing.T) {
+	r := newFormRequest("email=form@bar.com")
+	r.URL.RawQuery = "email=query@bar.com&page=2"
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "form@bar.com", kit.Value("email"))
+	assert.Equal(t, "2", kit.Value("page"))
+	assert.Equal(t, "", kit.Value("missing"))
+
+	r = newJSONRequest(`{"email": "json@bar.com", "age": 30, "admin": true, "name": null}`)
+	r.URL.RawQuery = "page=2"
+	kit, _ = newTestKit(r)
+	assert.Equal(t, "json@bar.com", kit.Value("email"))
+	assert.Equal(t, "30", kit.Value("age"))
+	assert.Equal(t, "true", kit.Value("admin"))
+	assert.Equal(t, "", kit.Value("name"))
+	assert.Equal(t, "2", kit.Value("page"))
+
+	// The body is cached, hence still available for binding.
+	user, err := Bind[createUserRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, 30, user.Age)
+}
+
+func TestBindValidateCustomValidator(t *testing.T) {
+	validate.RegisterValidator("slug", func(value any, param string) error {
+		s, _ := value.(string)
+		for _, r := range s {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return fmt.Errorf("is not a valid slug")
+			}
+		}
+		return nil
+	})
+	type createPostRequest struct {
+		Slug  string `json:"slug" validate:"required,slug"`
+		Email string `json:"email" validate:"email"`
+		Views int    `json:"views" validate:"min=1"`
+	}
+
+	kit, _ := newTestKit(newJSONRequest(`{"slug": "hello-world", "email": "foo@bar.com", "views": 5}`))
+	post, err := BindValidate[createPostRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello-world", post.Slug)
+	assert.Equal(t, 5, post.Views)
+
+	kit, _ = newTestKit(newJSONRequest(`{"slug": "Hello World", "email": "foo", "views": 0}`))
+	_, err = BindValidate[createPostRequest](kit)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{"is not a valid slug"}, validationErr.Errors["slug"])
+	assert.Len(t, validationErr.Errors["email"], 1)
+	assert.Equal(t, []string{"should be at least 1"}, validationErr.Errors["views"])
+}
+
+func TestMustBind(t *testing.T) {
+	var user createUserRequest
+	h := WithRecovery(Handler(func(kit *Kit) error {
+		user = MustBind[createUserRequest](kit)
+		return kit.JSON(http.StatusCreated, user)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newJSONRequest(`{"email": "foo@bar.com", "age": 30}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, createUserRequest{Email: "foo@bar.com", Age: 30}, user)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, newJSONRequest(`{"email": `))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBindBodyTooLarge(t *testing.T) {
+	h := Handler(func(kit *Kit) error {
+		_, err := Bind[createUserRequest](kit)
+		return err
+	})
+	w := httptest.NewRecorder()
+	r := newJSONRequest(`{"email": "foo@bar.com", "age": 30}`)
+	r.Body = http.MaxBytesReader(w, r.Body, 8)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	kit, w := newTestKit(newFormRequest("name=" + strings.Repeat("a", 64)))
+	kit.Request.Body = http.MaxBytesReader(w, kit.Request.Body, 8)
+	_, err := BindForm[struct {
+		Name string `form:"name"`
+	}](kit)
+	assert.ErrorIs(t, err, ErrRequestEntityTooLarge)
+}
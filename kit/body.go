@@ -0,0 +1,97 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+// ErrStreamingBody is returned by RawBody when the handler opted out of
+// body caching with UseStreamingBody.
+var ErrStreamingBody = errors.New("request body is streamed and can not be cached")
+
+// RawBody reads the complete body of the request and caches it, so it can
+// be read again by the binding helpers and subsequent calls to RawBody.
+// Reading fails with ErrRequestTimeout once the deadline of the request
+// context has passed, so a slowly trickling body can not hang a handler.
+// The request body is replaced with a reader over the cached bytes.
+func (kit *Kit) RawBody() ([]byte, error) {
+	if kit.streamingBody {
+		return nil, ErrStreamingBody
+	}
+	if kit.bodyCached {
+		return kit.rawBody, nil
+	}
+	if kit.Request.Body == nil {
+		kit.bodyCached = true
+		return nil, nil
+	}
+	b, err := io.ReadAll(kit.deadlineBody())
+	if err != nil {
+		return nil, err
+	}
+	kit.Request.Body.Close()
+	kit.Request.Body = io.NopCloser(bytes.NewReader(b))
+	kit.rawBody = b
+	kit.bodyCached = true
+	return b, nil
+}
+
+// UseStreamingBody opts the current handler out of body caching. The
+// binding helpers will read directly from the request body, which can
+// only be read once, instead of buffering it in memory. This is useful
+// for routes receiving large bodies.
+func (kit *Kit) UseStreamingBody() {
+	kit.streamingBody = true
+}
+
+// body returns a reader over the request body. Unless the handler uses a
+// streaming body, the body is cached so it can be read multiple times.
+func (kit *Kit) body() (io.Reader, error) {
+	if kit.streamingBody {
+		if kit.Request.Body == nil {
+			return bytes.NewReader(nil), nil
+		}
+		return kit.deadlineBody(), nil
+	}
+	b, err := kit.RawBody()
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// deadlineBody returns the request body bounded by the deadline of the
+// request context. The deadline is also set as the read deadline of the
+// connection, so a blocked read is interrupted as well.
+func (kit *Kit) deadlineBody() io.Reader {
+	ctx := kit.Request.Context()
+	if deadline, ok := ctx.Deadline(); ok {
+		// Not every ResponseWriter supports read deadlines, in which case
+		// the deadline is only checked between reads.
+		http.NewResponseController(kit.Response).SetReadDeadline(deadline)
+	}
+	return &deadlineReader{ctx: ctx, r: kit.Request.Body}
+}
+
+type deadlineReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = ErrRequestTimeout.Wrap(err)
+		}
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = ErrRequestTimeout.Wrap(err)
+	}
+	return n, err
+}
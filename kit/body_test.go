@@ -0,0 +1,83 @@
+package kit
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawBody(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com", "age": 30}`))
+	b, err := kit.RawBody()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"email": "foo@bar.com", "age": 30}`, string(b))
+
+	for i := 0; i < 2; i++ {
+		user, err := Bind[createUserRequest](kit)
+		assert.Nil(t, err)
+		assert.Equal(t, createUserRequest{Email: "foo@bar.com", Age: 30}, user)
+	}
+
+	b, err = kit.RawBody()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"email": "foo@bar.com", "age": 30}`, string(b))
+}
+
+func TestUseStreamingBody(t *testing.T) {
+	kit, _ := newTestKit(newJSONRequest(`{"email": "foo@bar.com", "age": 30}`))
+	kit.UseStreamingBody()
+	user, err := Bind[createUserRequest](kit)
+	assert.Nil(t, err)
+	assert.Equal(t, createUserRequest{Email: "foo@bar.com", Age: 30}, user)
+	assert.Nil(t, kit.rawBody)
+
+	_, err = kit.RawBody()
+	assert.ErrorIs(t, err, ErrStreamingBody)
+
+	// The stream has been consumed.
+	_, err = Bind[createUserRequest](kit)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+// slowReader returns one byte per read after a delay, like a client
+// trickling its body.
+type slowReader struct {
+	body  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	if len(r.body) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p[:1], r.body)
+	r.body = r.body[n:]
+	return n, nil
+}
+
+func TestBindSlowBody(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	body := &slowReader{body: []byte(`{"email": "foo@bar.com", "age": 30}`), delay: 5 * time.Millisecond}
+	r := httptest.NewRequest("POST", "/", body).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	kit, _ := newTestKit(r)
+	_, err := Bind[createUserRequest](kit)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBindJSONExpiredContext(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	r := newJSONRequest(`{"email": "foo@bar.com", "age": 30}`).WithContext(ctx)
+	kit, _ := newTestKit(r)
+	kit.UseStreamingBody()
+	var user createUserRequest
+	assert.ErrorIs(t, kit.BindJSON(&user), ErrRequestTimeout)
+}
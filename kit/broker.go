@@ -0,0 +1,107 @@
+package kit
+
+import (
+	"sync"
+)
+
+// SlowPolicy decides what a Broker does with a subscriber whose send
+// buffer is full.
+type SlowPolicy int
+
+const (
+	// DropOldest drops the oldest buffered event to make room for the
+	// new one.
+	DropOldest SlowPolicy = iota
+	// Disconnect unsubscribes the subscriber, ending its stream.
+	Disconnect
+)
+
+// BrokerConfig configures a Broker.
+type BrokerConfig struct {
+	// BufferSize is the number of events buffered per subscriber.
+	// Defaults to 16.
+	BufferSize int
+	// SlowPolicy is applied to subscribers whose buffer is full.
+	SlowPolicy SlowPolicy
+}
+
+// Broker fans out server-sent events to all of its subscribers. Each
+// subscriber has its own send buffer, hence a stuck client never blocks
+// publishing to the others.
+//
+//	var notifications = kit.NewBroker(kit.BrokerConfig{SlowPolicy: kit.Disconnect})
+//
+//	router.GET("/notifications", notifications.ServeSSE)
+//	notifications.Publish(kit.SSEEvent{Event: "notification", Data: msg})
+type Broker struct {
+	config BrokerConfig
+
+	mu   sync.Mutex
+	subs map[chan SSEEvent]struct{}
+}
+
+// NewBroker returns a new Broker.
+func NewBroker(config BrokerConfig) *Broker {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 16
+	}
+	return &Broker{
+		config: config,
+		subs:   make(map[chan SSEEvent]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving the published events and a
+// function to unsubscribe. The channel is closed once unsubscribed,
+// including when disconnected by the Disconnect policy.
+func (b *Broker) Subscribe() (<-chan SSEEvent, func()) {
+	ch := make(chan SSEEvent, b.config.BufferSize)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(ch)
+	}
+}
+
+// Publish sends the event to all subscribers without blocking.
+func (b *Broker) Publish(event SSEEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+		if b.config.SlowPolicy == Disconnect {
+			b.remove(ch)
+			continue
+		}
+		// Subscribers only receive, hence the event fits once the oldest
+		// one is dropped, unless the subscriber received it meanwhile.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- event
+	}
+}
+
+// ServeSSE streams the published events to the client until it
+// disconnects or is disconnected by the Disconnect policy.
+func (b *Broker) ServeSSE(kit *Kit) error {
+	events, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	return kit.SSE(events)
+}
+
+// remove unsubscribes the given channel, b.mu must be held.
+func (b *Broker) remove(ch chan SSEEvent) {
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
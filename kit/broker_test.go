@@ -0,0 +1,74 @@
+package kit
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveAll(events <-chan SSEEvent) []string {
+	var data []string
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return data
+			}
+			data = append(data, event.Data)
+		default:
+			return data
+		}
+	}
+}
+
+func TestBrokerDropOldest(t *testing.T) {
+	broker := NewBroker(BrokerConfig{BufferSize: 2, SlowPolicy: DropOldest})
+	slow, unsubscribeSlow := broker.Subscribe()
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := broker.Subscribe()
+	defer unsubscribeFast()
+
+	var received []string
+	for i := 1; i <= 4; i++ {
+		broker.Publish(SSEEvent{Data: fmt.Sprint(i)})
+		received = append(received, receiveAll(fast)...)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4"}, received)
+	assert.Equal(t, []string{"3", "4"}, receiveAll(slow))
+}
+
+func TestBrokerDisconnect(t *testing.T) {
+	broker := NewBroker(BrokerConfig{BufferSize: 2, SlowPolicy: Disconnect})
+	slow, unsubscribe := broker.Subscribe()
+	for i := 1; i <= 4; i++ {
+		broker.Publish(SSEEvent{Data: fmt.Sprint(i)})
+	}
+	assert.Equal(t, "1", (<-slow).Data)
+	assert.Equal(t, "2", (<-slow).Data)
+	_, ok := <-slow
+	assert.False(t, ok)
+	// Unsubscribing a disconnected subscriber is a no-op.
+	unsubscribe()
+}
+
+func TestBrokerServeSSE(t *testing.T) {
+	broker := NewBroker(BrokerConfig{BufferSize: 1, SlowPolicy: Disconnect})
+	kit, w := newTestKit(httptest.NewRequest("GET", "/events", nil))
+	done := make(chan error)
+	go func() { done <- broker.ServeSSE(kit) }()
+	// Publish until the stream is disconnected, at which point the
+	// handler returns.
+	for i := 0; ; i++ {
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+			assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), "data: ")
+			return
+		default:
+			broker.Publish(SSEEvent{Data: fmt.Sprint(i)})
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package kit
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// ComponentCache stores rendered components used by RenderWithCache.
+type ComponentCache interface {
+	// Get returns the cached bytes for the given key, if present
+	// and not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the given bytes for the given key for ttl.
+	Set(key string, b []byte, ttl time.Duration)
+	// Delete removes the given key from the cache.
+	Delete(key string)
+}
+
+var componentCache ComponentCache = NewMemoryComponentCache()
+
+// UseComponentCache sets the ComponentCache used by RenderWithCache.
+func UseComponentCache(c ComponentCache) { componentCache = c }
+
+// InvalidateComponent removes the rendered component cached under the
+// given key.
+func InvalidateComponent(key string) {
+	componentCache.Delete(key)
+}
+
+// RenderWithCache renders the given component, caching the rendered
+// output under the given key for ttl. Subsequent calls with the same key
+// write the cached output without rendering the component. Like Render,
+// the response defaults to RenderContentType and RenderDefaultStatus.
+func (kit *Kit) RenderWithCache(key string, ttl time.Duration, c templ.Component) error {
+	if kit.aborted {
+		return nil
+	}
+	b, ok := componentCache.Get(key)
+	if !ok {
+		buf := &bytes.Buffer{}
+		if err := c.Render(kit.RenderContext(), buf); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+		componentCache.Set(key, b, ttl)
+	}
+	header := kit.Response.Header()
+	if len(header.Get("Content-Type")) == 0 && len(RenderContentType) > 0 {
+		header.Set("Content-Type", RenderContentType)
+	}
+	if RenderDefaultStatus != http.StatusOK {
+		// The status is otherwise written by the first write.
+		kit.Response.WriteHeader(RenderDefaultStatus)
+	}
+	_, err := kit.Response.Write(b)
+	return err
+}
+
+type cacheEntry struct {
+	b         []byte
+	expiresAt time.Time
+}
+
+// componentCacheSweepInterval is how often MemoryComponentCache removes
+// the expired entries that were not read again.
+const componentCacheSweepInterval = time.Minute
+
+// MemoryComponentCache is an in-memory ComponentCache, which is the
+// default cache used by RenderWithCache. Expired entries are removed when
+// read and periodically when new entries are set.
+type MemoryComponentCache struct {
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	lastSweep time.Time
+}
+
+// NewMemoryComponentCache returns a new MemoryComponentCache.
+func NewMemoryComponentCache() *MemoryComponentCache {
+	return &MemoryComponentCache{
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *MemoryComponentCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.b, true
+}
+
+func (c *MemoryComponentCache) Set(key string, b []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > componentCacheSweepInterval {
+		for key, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = cacheEntry{
+		b:         b,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+func (c *MemoryComponentCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
@@ -0,0 +1,126 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCountingComponent(renders *int) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		*renders++
+		_, err := fmt.Fprintf(w, "render %d", *renders)
+		return err
+	})
+}
+
+func TestRenderWithCache(t *testing.T) {
+	renders := 0
+	component := newCountingComponent(&renders)
+	defer InvalidateComponent("sidebar")
+
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.RenderWithCache("sidebar", time.Minute, component))
+	assert.Equal(t, "render 1", w.Body.String())
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.RenderWithCache("sidebar", time.Minute, component))
+	assert.Equal(t, "render 1", w.Body.String())
+	assert.Equal(t, 1, renders)
+
+	InvalidateComponent("sidebar")
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.RenderWithCache("sidebar", time.Minute, component))
+	assert.Equal(t, "render 2", w.Body.String())
+}
+
+func TestRenderWithCacheExpiry(t *testing.T) {
+	renders := 0
+	component := newCountingComponent(&renders)
+	defer InvalidateComponent("footer")
+
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.RenderWithCache("footer", time.Millisecond, component))
+	time.Sleep(5 * time.Millisecond)
+
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.RenderWithCache("footer", time.Millisecond, component))
+	assert.Equal(t, "render 2", w.Body.String())
+	assert.Equal(t, 2, renders)
+}
+
+func TestRenderWithCacheHeaders(t *testing.T) {
+	renders := 0
+	component := newCountingComponent(&renders)
+	defer InvalidateComponent("header")
+
+	for i := 0; i < 2; i++ {
+		kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+		assert.Nil(t, kit.RenderWithCache("header", time.Minute, component))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "render 1", w.Body.String())
+	}
+}
+
+func TestRenderWithCacheAborted(t *testing.T) {
+	renders := 0
+	component := newCountingComponent(&renders)
+	defer InvalidateComponent("aborted")
+
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.ErrorIs(t, kit.Abort(http.StatusNoContent), ErrAborted)
+	assert.Nil(t, kit.RenderWithCache("aborted", time.Minute, component))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, 0, renders)
+}
+
+func TestMemoryComponentCacheEviction(t *testing.T) {
+	cache := NewMemoryComponentCache()
+	cache.Set("expired", []byte("foo"), -time.Second)
+	_, ok := cache.Get("expired")
+	assert.False(t, ok)
+	assert.Empty(t, cache.entries)
+
+	// Expired entries that are never read again are swept on Set.
+	cache.Set("unread", []byte("foo"), -time.Second)
+	cache.lastSweep = time.Time{}
+	cache.Set("fresh", []byte("bar"), time.Minute)
+	assert.Len(t, cache.entries, 1)
+	b, ok := cache.Get("fresh")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", string(b))
+}
+
+// statusRecorder counts the calls to WriteHeader.
+type statusRecorder struct {
+	*httptest.ResponseRecorder
+	writeHeaders int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.writeHeaders++
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func TestRenderWithCacheHandlerStatus(t *testing.T) {
+	renders := 0
+	component := newCountingComponent(&renders)
+	defer InvalidateComponent("created")
+
+	w := &statusRecorder{ResponseRecorder: httptest.NewRecorder()}
+	kit := &Kit{Response: w, Request: httptest.NewRequest("POST", "/", nil)}
+	kit.Response.WriteHeader(http.StatusCreated)
+	assert.Nil(t, kit.RenderWithCache("created", time.Minute, component))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, w.writeHeaders)
+	assert.Equal(t, "render 1", w.Body.String())
+}
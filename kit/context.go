@@ -0,0 +1,89 @@
+package kit
+
+import (
+	"context"
+)
+
+// ContextKey is a key for values stored in a context. Every key returned
+// by NewContextKey is distinct, even if created with the same name, so
+// values stored by different packages never collide.
+type ContextKey struct {
+	key *contextKey
+}
+
+type contextKey struct {
+	name string
+}
+
+// NewContextKey returns a new ContextKey. The name is only used for
+// debugging.
+//
+//	var TenantKey = kit.NewContextKey("tenant")
+func NewContextKey(name string) ContextKey {
+	return ContextKey{key: &contextKey{name: name}}
+}
+
+func (k ContextKey) String() string {
+	if k.key == nil {
+		return "kit context key"
+	}
+	return "kit context key " + k.key.name
+}
+
+// WithContextValue returns a copy of ctx holding the value under the
+// given key.
+//
+//	ctx = kit.WithContextValue(ctx, TenantKey, tenant)
+func WithContextValue(ctx context.Context, key ContextKey, value any) context.Context {
+	return context.WithValue(ctx, key, value)
+}
+
+// FromContext returns the value of type T stored in ctx under the given
+// key, and whether it is present.
+//
+//	tenant, ok := kit.FromContext[*Tenant](ctx, TenantKey)
+func FromContext[T any](ctx context.Context, key ContextKey) (T, bool) {
+	value, ok := ctx.Value(key).(T)
+	return value, ok
+}
+
+// The keys of the values kit stores in the request context. They are
+// unexported, the values are accessed with the functions below.
+var (
+	kitKey       = NewContextKey("kit")
+	authKey      = NewContextKey("auth")
+	flashKey     = NewContextKey("flash")
+	localeKey    = NewContextKey("locale")
+	requestIDKey = NewContextKey("request_id")
+)
+
+// WithAuth returns a copy of ctx holding the given Auth, which is what
+// WithAuthentication does for the authenticated requests.
+func WithAuth(ctx context.Context, auth Auth) context.Context {
+	return WithContextValue(ctx, authKey, auth)
+}
+
+// AuthFromContext returns the Auth of the request set by
+// WithAuthentication or WithAuth.
+func AuthFromContext(ctx context.Context) (Auth, bool) {
+	return FromContext[Auth](ctx, authKey)
+}
+
+// FlashesFromContext returns the flash messages RenderContext stores for
+// the components.
+func FlashesFromContext(ctx context.Context) []string {
+	flashes, _ := FromContext[[]string](ctx, flashKey)
+	return flashes
+}
+
+// LocaleFromContext returns the resolved locale of the request
+// RenderContext stores for the components.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	return FromContext[string](ctx, localeKey)
+}
+
+// RequestIDFromContext returns the ID of the request set by WithRequestID,
+// which is useful in code that does not have access to the Kit.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return FromContext[string](ctx, requestIDKey)
+}
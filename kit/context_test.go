@@ -0,0 +1,77 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	tenantKey := NewContextKey("tenant")
+	userKey := NewContextKey("user")
+	otherTenantKey := NewContextKey("tenant")
+
+	ctx := context.Background()
+	ctx = WithContextValue(ctx, tenantKey, "acme")
+	ctx = WithContextValue(ctx, userKey, 42)
+	ctx = context.WithValue(ctx, "tenant", "app")
+
+	tenant, ok := FromContext[string](ctx, tenantKey)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenant)
+
+	user, ok := FromContext[int](ctx, userKey)
+	assert.True(t, ok)
+	assert.Equal(t, 42, user)
+
+	_, ok = FromContext[string](ctx, otherTenantKey)
+	assert.False(t, ok)
+
+	_, ok = FromContext[string](ctx, userKey)
+	assert.False(t, ok)
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	var id string
+	h := WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ = RequestIDFromContext(r.Context())
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(RequestIDHeader, "client-id")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "client-id", id)
+
+	_, ok := RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestContextKeysDoNotCollide(t *testing.T) {
+	// App code using the same names, or the same key types, as the keys
+	// of kit never reads or overwrites their values.
+	ctx := context.Background()
+	ctx = WithContextValue(ctx, requestIDKey, "request-1")
+	ctx = WithAuth(ctx, testIdentity{id: "42"})
+	ctx = WithContextValue(ctx, localeKey, "de")
+	ctx = WithContextValue(ctx, NewContextKey("request_id"), "app-request")
+	ctx = WithContextValue(ctx, NewContextKey("auth"), "app-auth")
+	ctx = context.WithValue(ctx, "locale", "fr")
+
+	id, ok := RequestIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "request-1", id)
+
+	auth, ok := AuthFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, testIdentity{id: "42"}, auth)
+
+	locale, ok := LocaleFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "de", locale)
+
+	assert.Nil(t, FlashesFromContext(ctx))
+	_, ok = FromContext[string](ctx, flashKey)
+	assert.False(t, ok)
+}
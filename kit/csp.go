@@ -0,0 +1,68 @@
+package kit
+
+import (
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// CSPNonce returns the Content Security Policy nonce of the current
+// request, generating it on first use. The nonce is added to the
+// script-src directive of the Content-Security-Policy response header,
+// which is set if no policy was configured yet. Components rendered with
+// Render can access the nonce with view.CSPNonce, and templ script
+// components use it automatically.
+//
+//	<script nonce={ view.CSPNonce(ctx) }>...</script>
+func (kit *Kit) CSPNonce() string {
+	if len(kit.cspNonce) > 0 {
+		return kit.cspNonce
+	}
+	if nonce := templ.GetNonce(kit.Request.Context()); len(nonce) > 0 {
+		return nonce
+	}
+	nonce, err := GenerateToken(16)
+	if err != nil {
+		panic(err)
+	}
+	header := kit.Response.Header()
+	header.Set("Content-Security-Policy", addCSPNonce(header.Get("Content-Security-Policy"), nonce))
+	kit.cspNonce = nonce
+	if kit.renderCtx != nil {
+		kit.renderCtx = templ.WithNonce(kit.renderCtx, nonce)
+	}
+	return nonce
+}
+
+// addCSPNonce adds the nonce to the script-src directive of the given
+// policy. Without a script-src directive, the sources of default-src are
+// used, since script-src would otherwise override them.
+func addCSPNonce(policy, nonce string) string {
+	var (
+		source     = "'nonce-" + nonce + "'"
+		directives []string
+		defaultSrc = []string{"'self'"}
+		hasScript  bool
+	)
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "script-src":
+			fields = append(fields, source)
+			hasScript = true
+		case "default-src":
+			if len(fields) > 1 {
+				defaultSrc = fields[1:]
+			}
+		}
+		directives = append(directives, strings.Join(fields, " "))
+	}
+	if !hasScript {
+		script := append([]string{"script-src"}, defaultSrc...)
+		directives = append(directives, strings.Join(append(script, source), " "))
+	}
+	return strings.Join(directives, "; ")
+}
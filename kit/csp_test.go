@@ -0,0 +1,40 @@
+package kit
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPNonce(t *testing.T) {
+	var nonce string
+	component := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, `<script nonce="`+templ.GetNonce(ctx)+`"></script>`)
+		return err
+	})
+	w := httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		nonce = kit.CSPNonce()
+		assert.Equal(t, nonce, kit.CSPNonce())
+		return kit.Render(component)
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.NotEmpty(t, nonce)
+	assert.Equal(t, "script-src 'self' 'nonce-"+nonce+"'", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, `<script nonce="`+nonce+`"></script>`, w.Body.String())
+}
+
+func TestCSPNonceExistingPolicy(t *testing.T) {
+	tests := map[string]string{
+		"default-src 'self' cdn.com; img-src *": "default-src 'self' cdn.com; img-src *; script-src 'self' cdn.com 'nonce-foo'",
+		"script-src 'self';":                    "script-src 'self' 'nonce-foo'",
+		"img-src *":                             "img-src *; script-src 'self' 'nonce-foo'",
+	}
+	for policy, want := range tests {
+		assert.Equal(t, want, addCSPNonce(policy, "foo"))
+	}
+}
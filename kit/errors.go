@@ -0,0 +1,285 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/a-h/templ"
+)
+
+// Sentinel errors for common HTTP conditions. Handlers can return them
+// directly or customize them with WithCode and Wrap.
+//
+//	return kit.Fail(kit.ErrNotFound)
+var (
+	ErrBadRequest            = NewAPIError(http.StatusBadRequest, "")
+	ErrUnauthorized          = NewAPIError(http.StatusUnauthorized, "")
+	ErrForbidden             = NewAPIError(http.StatusForbidden, "")
+	ErrNotFound              = NewAPIError(http.StatusNotFound, "")
+	ErrMethodNotAllowed      = NewAPIError(http.StatusMethodNotAllowed, "")
+	ErrNotAcceptable         = NewAPIError(http.StatusNotAcceptable, "")
+	ErrRequestTimeout        = NewAPIError(http.StatusRequestTimeout, "")
+	ErrConflict              = NewAPIError(http.StatusConflict, "")
+	ErrGone                  = NewAPIError(http.StatusGone, "")
+	ErrRequestEntityTooLarge = NewAPIError(http.StatusRequestEntityTooLarge, "")
+	ErrUnsupportedMediaType  = NewAPIError(http.StatusUnsupportedMediaType, "")
+	ErrUnprocessableEntity   = NewAPIError(http.StatusUnprocessableEntity, "")
+	ErrTooManyRequests       = NewAPIError(http.StatusTooManyRequests, "")
+	ErrInternalServer        = NewAPIError(http.StatusInternalServerError, "")
+	ErrServiceUnavailable    = NewAPIError(http.StatusServiceUnavailable, "")
+	ErrGatewayTimeout        = NewAPIError(http.StatusGatewayTimeout, "")
+)
+
+// APIError is an error carrying the HTTP status that should be sent to
+// the client. Handlers can return an APIError and the default error
+// handler will respond with its status and message.
+type APIError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	// Type and Instance are the URI references identifying the problem
+	// type and the specific occurrence in problem+json responses.
+	Type     string `json:"type,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Err      error  `json:"-"`
+}
+
+// NewAPIError returns a new APIError with the given status and message.
+// If message is empty the status text will be used.
+func NewAPIError(status int, message string) *APIError {
+	if len(message) == 0 {
+		message = http.StatusText(status)
+	}
+	return &APIError{
+		Status:  status,
+		Message: message,
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is an APIError with the same status and
+// message, hence errors.Is(ErrNotFound.Wrap(err), ErrNotFound) is true.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return e.Status == t.Status && e.Message == t.Message
+}
+
+// WithCode returns a copy of the APIError with the given application
+// specific error code.
+func (e *APIError) WithCode(code string) *APIError {
+	err := *e
+	err.Code = code
+	return &err
+}
+
+// WithType returns a copy of the APIError with the given problem type URI,
+// see UseProblemDetails.
+func (e *APIError) WithType(uri string) *APIError {
+	err := *e
+	err.Type = uri
+	return &err
+}
+
+// WithInstance returns a copy of the APIError with the given URI of the
+// specific occurrence of the problem, see UseProblemDetails.
+func (e *APIError) WithInstance(uri string) *APIError {
+	err := *e
+	err.Instance = uri
+	return &err
+}
+
+// Wrap returns a copy of the APIError wrapping the given cause.
+// The cause is only exposed to the client in development.
+func (e *APIError) Wrap(cause error) *APIError {
+	err := *e
+	err.Err = cause
+	return &err
+}
+
+// ErrorPageFunc returns the component rendered by the default error
+// handler for HTML requests.
+type ErrorPageFunc func(kit *Kit, status int, err error) templ.Component
+
+var errorPage ErrorPageFunc
+
+// UseErrorPage sets the component the default error handler renders
+// for HTML requests.
+//
+//	kit.UseErrorPage(func(kit *kit.Kit, status int, err error) templ.Component {
+//		return errors.ErrorPage(status)
+//	})
+func UseErrorPage(fn ErrorPageFunc) { errorPage = fn }
+
+// ErrorVerbosity controls how much of an error the default error handler
+// exposes to the client.
+type ErrorVerbosity int
+
+const (
+	// ErrorVerbosityAuto is detailed in development and minimal in any
+	// other environment.
+	ErrorVerbosityAuto ErrorVerbosity = iota
+	// ErrorVerbosityMinimal only exposes the status and the message of
+	// an APIError, other errors result in a generic message.
+	ErrorVerbosityMinimal
+	// ErrorVerbosityDetailed additionally exposes the error code, the
+	// chain of wrapped errors and the stack of recovered panics.
+	ErrorVerbosityDetailed
+)
+
+var errorVerbosity = ErrorVerbosityAuto
+
+var problemDetails bool
+
+// UseProblemDetails sets whether the default error handler writes an
+// APIError as an RFC 7807 application/problem+json body to clients
+// accepting it.
+func UseProblemDetails(enabled bool) { problemDetails = enabled }
+
+// SetErrorVerbosity overrides the verbosity of the default error handler,
+// which is useful for staging environments.
+func SetErrorVerbosity(level ErrorVerbosity) { errorVerbosity = level }
+
+func detailedErrors() bool {
+	if errorVerbosity == ErrorVerbosityAuto {
+		return IsDevelopment()
+	}
+	return errorVerbosity == ErrorVerbosityDetailed
+}
+
+// DefaultErrorHandler is the error handler used when no custom handler
+// is set with UseErrorHandler. An APIError is written as JSON with its
+// status, any other error results in a 500. With detailed errors, see
+// SetErrorVerbosity, the JSON body additionally includes the error code,
+// the chain of wrapped errors and the stack of a recovered panic, and
+// other errors expose their message. HTML requests will be served the
+// error page set with UseErrorPage, clients accepting problem+json a
+// problem details body if enabled with UseProblemDetails.
+func DefaultErrorHandler(kit *Kit, err error) {
+	var apiErr *APIError
+	if errorPage != nil && isHTMLRequest(kit.Request) {
+		status := http.StatusInternalServerError
+		if errors.As(err, &apiErr) {
+			status = apiErr.Status
+		}
+		renderErrorPage(kit, status, err)
+		return
+	}
+	detailed := detailedErrors()
+	if !errors.As(err, &apiErr) {
+		msg := http.StatusText(http.StatusInternalServerError)
+		if detailed {
+			msg = err.Error()
+		}
+		kit.Text(http.StatusInternalServerError, msg)
+		return
+	}
+	problem := problemDetails && acceptsProblemJSON(kit.Request)
+	body := map[string]any{
+		"status":  apiErr.Status,
+		"message": apiErr.Message,
+	}
+	if problem {
+		body = problemBody(apiErr)
+	}
+	if detailed {
+		if len(apiErr.Code) > 0 {
+			body["code"] = apiErr.Code
+		}
+		if apiErr.Err != nil {
+			body["errors"] = errorChain(apiErr.Err)
+		}
+		if stack, ok := kit.Get(RecoveredStackKey).([]byte); ok {
+			body["stack"] = string(stack)
+		}
+	}
+	if problem {
+		b, err := marshalJSON(body)
+		if err != nil {
+			kit.Text(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+		kit.write(apiErr.Status, "application/problem+json", b)
+		return
+	}
+	kit.JSON(apiErr.Status, body)
+}
+
+// problemBody returns the RFC 7807 problem details of the given error.
+func problemBody(apiErr *APIError) map[string]any {
+	problemType := apiErr.Type
+	if len(problemType) == 0 {
+		problemType = "about:blank"
+	}
+	body := map[string]any{
+		"type":   problemType,
+		"title":  http.StatusText(apiErr.Status),
+		"status": apiErr.Status,
+		"detail": apiErr.Message,
+	}
+	if len(apiErr.Instance) > 0 {
+		body["instance"] = apiErr.Instance
+	}
+	return body
+}
+
+func acceptsProblemJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/problem+json")
+}
+
+// IsClientDisconnect reports whether the error means the client went away,
+// e.g. a broken pipe while writing the response or a canceled request
+// context. Responding to such errors is pointless.
+func IsClientDisconnect(err error) bool {
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, context.Canceled)
+}
+
+// renderErrorPage renders the error page into a buffer first, so we can
+// still fall back to plain text if rendering fails.
+func renderErrorPage(kit *Kit, status int, err error) {
+	buf := &bytes.Buffer{}
+	if renderErr := errorPage(kit, status, err).Render(kit.RenderContext(), buf); renderErr != nil {
+		slog.Error("failed to render error page", "err", renderErr)
+		kit.Text(status, http.StatusText(status))
+		return
+	}
+	kit.Response.Header().Set("Content-Type", withCharset("text/html"))
+	kit.Response.WriteHeader(status)
+	kit.Response.Write(buf.Bytes())
+}
+
+func isHTMLRequest(r *http.Request) bool {
+	return len(r.Header.Get("HX-Request")) > 0 ||
+		strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// errorChain returns the messages of the given error and all
+// the errors it wraps.
+func errorChain(err error) []string {
+	chain := []string{}
+	for err != nil {
+		chain = append(chain, err.Error())
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
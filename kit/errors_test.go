@@ -0,0 +1,260 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	cause := errors.New("duplicate key")
+	err := NewAPIError(http.StatusConflict, "").WithCode("user_exists").Wrap(cause)
+	assert.Equal(t, "Conflict: duplicate key", err.Error())
+	assert.Equal(t, "user_exists", err.Code)
+	assert.ErrorIs(t, err, cause)
+}
+
+func serveError(err error) map[string]any {
+	w := httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		return err
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := map[string]any{}
+	json.NewDecoder(w.Body).Decode(&body)
+	return body
+}
+
+func TestDefaultErrorHandlerDevelopment(t *testing.T) {
+	t.Setenv("SUPERKIT_ENV", "development")
+	cause := errors.New("duplicate key")
+	err := NewAPIError(http.StatusConflict, "user already exists").WithCode("user_exists").Wrap(cause)
+	body := serveError(err)
+	assert.Equal(t, float64(http.StatusConflict), body["status"])
+	assert.Equal(t, "user already exists", body["message"])
+	assert.Equal(t, "user_exists", body["code"])
+	assert.Equal(t, []any{"duplicate key"}, body["errors"])
+}
+
+func TestDefaultErrorHandlerProduction(t *testing.T) {
+	t.Setenv("SUPERKIT_ENV", "production")
+	cause := errors.New("duplicate key")
+	err := NewAPIError(http.StatusConflict, "user already exists").WithCode("user_exists").Wrap(cause)
+	body := serveError(err)
+	assert.Equal(t, map[string]any{
+		"status":  float64(http.StatusConflict),
+		"message": "user already exists",
+	}, body)
+}
+
+func TestDefaultErrorHandlerInternalError(t *testing.T) {
+	h := Handler(func(kit *Kit) error {
+		return errors.New("connection refused")
+	})
+
+	t.Setenv("SUPERKIT_ENV", "development")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "connection refused")
+
+	t.Setenv("SUPERKIT_ENV", "production")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+	assert.Equal(t, "Internal Server Error", w.Body.String())
+}
+
+func TestSetErrorVerbosity(t *testing.T) {
+	t.Setenv("SUPERKIT_ENV", "production")
+	SetErrorVerbosity(ErrorVerbosityDetailed)
+	defer SetErrorVerbosity(ErrorVerbosityAuto)
+
+	body := serveError(ErrConflict.Wrap(errors.New("duplicate key")))
+	assert.Equal(t, []any{"duplicate key"}, body["errors"])
+
+	t.Setenv("SUPERKIT_ENV", "development")
+	SetErrorVerbosity(ErrorVerbosityMinimal)
+	body = serveError(ErrConflict.Wrap(errors.New("duplicate key")))
+	assert.Nil(t, body["errors"])
+}
+
+func TestSentinelErrors(t *testing.T) {
+	tests := map[*APIError]int{
+		ErrBadRequest:          http.StatusBadRequest,
+		ErrUnauthorized:        http.StatusUnauthorized,
+		ErrForbidden:           http.StatusForbidden,
+		ErrNotFound:            http.StatusNotFound,
+		ErrMethodNotAllowed:    http.StatusMethodNotAllowed,
+		ErrConflict:            http.StatusConflict,
+		ErrGone:                http.StatusGone,
+		ErrUnprocessableEntity: http.StatusUnprocessableEntity,
+		ErrTooManyRequests:     http.StatusTooManyRequests,
+		ErrInternalServer:      http.StatusInternalServerError,
+		ErrServiceUnavailable:  http.StatusServiceUnavailable,
+		ErrGatewayTimeout:      http.StatusGatewayTimeout,
+	}
+	for sentinel, status := range tests {
+		w := httptest.NewRecorder()
+		Handler(func(kit *Kit) error {
+			return kit.Fail(sentinel)
+		}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, status, w.Code)
+	}
+}
+
+func TestSentinelErrorsIs(t *testing.T) {
+	err := ErrNotFound.Wrap(errors.New("no rows"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+	assert.Nil(t, ErrNotFound.Err)
+}
+
+func TestErrorPage(t *testing.T) {
+	UseErrorPage(func(kit *Kit, status int, err error) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			if status == http.StatusTeapot {
+				return errors.New("render failed")
+			}
+			_, err := fmt.Fprintf(w, "<h1>%d page</h1>", status)
+			return err
+		})
+	})
+	defer UseErrorPage(nil)
+
+	h := Handler(func(kit *Kit) error {
+		if kit.Request.URL.Path == "/teapot" {
+			return NewAPIError(http.StatusTeapot, "")
+		}
+		return ErrNotFound
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/html,application/xhtml+xml")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "<h1>404 page</h1>", w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	// Non HTML requests still receive JSON.
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	// Failing to render the error page falls back to plain text.
+	r = httptest.NewRequest("GET", "/teapot", nil)
+	r.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, http.StatusText(http.StatusTeapot), w.Body.String())
+}
+
+func TestProblemDetails(t *testing.T) {
+	UseProblemDetails(true)
+	defer UseProblemDetails(false)
+
+	err := ErrNotFound.
+		WithType("https://example.com/problems/user-not-found").
+		WithInstance("/users/1")
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("Accept", "application/problem+json, application/json")
+	w := httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		return err
+	}).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	body := map[string]any{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, map[string]any{
+		"type":     "https://example.com/problems/user-not-found",
+		"title":    "Not Found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "Not Found",
+		"instance": "/users/1",
+	}, body)
+}
+
+func TestProblemDetailsNotAccepted(t *testing.T) {
+	UseProblemDetails(true)
+	defer UseProblemDetails(false)
+
+	w := httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		return ErrNotFound
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestIsClientDisconnect(t *testing.T) {
+	brokenPipe := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+	assert.True(t, IsClientDisconnect(brokenPipe))
+	assert.True(t, IsClientDisconnect(fmt.Errorf("render: %w", syscall.ECONNRESET)))
+	assert.True(t, IsClientDisconnect(context.Canceled))
+	assert.False(t, IsClientDisconnect(errors.New("foo")))
+	assert.False(t, IsClientDisconnect(ErrNotFound))
+}
+
+// brokenPipeRecorder fails all writes like a connection closed by the
+// client.
+type brokenPipeRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (w brokenPipeRecorder) Write(b []byte) (int, error) {
+	return 0, &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+}
+
+func TestHandlerClientDisconnect(t *testing.T) {
+	called := false
+	UseErrorHandler(func(kit *Kit, err error) {
+		called = true
+	})
+	defer UseErrorHandler(DefaultErrorHandler)
+
+	w := brokenPipeRecorder{httptest.NewRecorder()}
+	Handler(func(kit *Kit) error {
+		return kit.Text(http.StatusOK, "hello")
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.False(t, called)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	Handler(func(kit *Kit) error {
+		return kit.Request.Context().Err()
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assert.False(t, called)
+}
+
+func TestHandlerCanceledByHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		ctx, cancel := context.WithCancel(kit.Request.Context())
+		cancel()
+		return fmt.Errorf("query: %w", ctx.Err())
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	Handler(func(kit *Kit) error {
+		return &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+	}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,139 @@
+package kit
+
+import (
+	"sync"
+	"time"
+)
+
+// FormTokenSessionName is the name of the session binding form tokens to
+// the user that requested them.
+const FormTokenSessionName = "superkit-formtoken"
+
+// FormTokenTTL is how long a token issued by FormToken stays valid.
+var FormTokenTTL = time.Hour
+
+// FormTokenStore keeps the issued form tokens on the server until they
+// are used or expire, which makes them single use even when a request
+// is replayed with the same cookies.
+type FormTokenStore interface {
+	// Save stores the key until it expires.
+	Save(key string, expires time.Time) error
+	// Consume removes the key, reporting whether it was stored and not
+	// expired.
+	Consume(key string) (bool, error)
+}
+
+// formTokens defaults to an in-memory store, hence tokens are only valid
+// for the process that issued them.
+var formTokens FormTokenStore = newMemoryFormTokenStore()
+
+// UseFormTokenStore sets the FormTokenStore backing FormToken and
+// VerifyFormToken, e.g. a shared store when running multiple instances.
+func UseFormTokenStore(s FormTokenStore) { formTokens = s }
+
+// FormToken issues a single use token for the form with the given ID.
+// Embed it in the form and check it with VerifyFormToken when handling
+// the submission, which guards against duplicate submissions, e.g. double
+// clicks, and replayed requests. The token is bound to the session of the
+// user, which is created if needed, hence FormToken must be called before
+// the response is written.
+//
+//	<input type="hidden" name="form_token" value={ token }/>
+func (kit *Kit) FormToken(formID string) (string, error) {
+	sessionID, err := kit.formTokenSession(true)
+	if err != nil {
+		return "", err
+	}
+	token, err := GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(FormTokenTTL)
+	if err := formTokens.Save(formTokenKey(formID, sessionID, token), expires); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyFormToken reports whether the token was issued by FormToken for
+// the form with the given ID in the session of the current request and
+// not used before. The token is consumed, verifying it a second time
+// returns false.
+func (kit *Kit) VerifyFormToken(formID, token string) bool {
+	if len(token) == 0 {
+		return false
+	}
+	sessionID, err := kit.formTokenSession(false)
+	if err != nil || len(sessionID) == 0 {
+		return false
+	}
+	ok, err := formTokens.Consume(formTokenKey(formID, sessionID, token))
+	return err == nil && ok
+}
+
+// formTokenSession returns the ID binding form tokens to the session of
+// the current request, creating it if create is set.
+func (kit *Kit) formTokenSession(create bool) (string, error) {
+	sess := kit.GetSession(FormTokenSessionName)
+	if id, ok := sess.Values["id"].(string); ok && len(id) > 0 {
+		return id, nil
+	}
+	if !create {
+		return "", nil
+	}
+	id, err := GenerateToken(16)
+	if err != nil {
+		return "", err
+	}
+	sess.Values["id"] = id
+	if err := sess.Save(kit.Request, kit.Response); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func formTokenKey(formID, sessionID, token string) string {
+	return formID + ":" + sessionID + ":" + token
+}
+
+type memoryFormTokenStore struct {
+	mu        sync.Mutex
+	tokens    map[string]time.Time
+	lastSweep time.Time
+}
+
+func newMemoryFormTokenStore() *memoryFormTokenStore {
+	return &memoryFormTokenStore{
+		tokens: make(map[string]time.Time),
+	}
+}
+
+func (s *memoryFormTokenStore) Save(key string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired tokens that are never submitted are swept periodically.
+	now := time.Now()
+	if now.Sub(s.lastSweep) > FormTokenTTL {
+		for key, expiresAt := range s.tokens {
+			if now.After(expiresAt) {
+				delete(s.tokens, key)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.tokens[key] = expires
+	return nil
+}
+
+func (s *memoryFormTokenStore) Consume(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.tokens[key]
+	if !ok {
+		return false, nil
+	}
+	delete(s.tokens, key)
+	return time.Now().Before(expiresAt), nil
+}
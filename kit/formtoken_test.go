@@ -0,0 +1,77 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// issueFormToken issues a token for the form and returns it together with
+// the cookies of the session it is bound to.
+func issueFormToken(t *testing.T, formID string) (string, []*http.Cookie) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/checkout", nil))
+	token, err := kit.FormToken(formID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+	return token, w.Result().Cookies()
+}
+
+func newFormTokenKit(cookies []*http.Cookie) *Kit {
+	r := httptest.NewRequest("POST", "/checkout", nil)
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	kit, _ := newTestKit(r)
+	return kit
+}
+
+func TestFormToken(t *testing.T) {
+	token, cookies := issueFormToken(t, "checkout")
+
+	kit := newFormTokenKit(cookies)
+	assert.False(t, kit.VerifyFormToken("profile", token))
+	assert.True(t, kit.VerifyFormToken("checkout", token))
+	assert.False(t, kit.VerifyFormToken("checkout", ""))
+}
+
+func TestFormTokenReplay(t *testing.T) {
+	token, cookies := issueFormToken(t, "checkout")
+
+	// A double click sends both submissions with the same cookies.
+	assert.True(t, newFormTokenKit(cookies).VerifyFormToken("checkout", token))
+	assert.False(t, newFormTokenKit(cookies).VerifyFormToken("checkout", token))
+}
+
+func TestFormTokenSession(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/checkout", nil))
+	first, err := kit.FormToken("checkout")
+	assert.Nil(t, err)
+	second, err := kit.FormToken("checkout")
+	assert.Nil(t, err)
+
+	// Both tokens are bound to the same session, e.g. two open tabs.
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.True(t, newFormTokenKit(cookies).VerifyFormToken("checkout", second))
+	assert.True(t, newFormTokenKit(cookies).VerifyFormToken("checkout", first))
+}
+
+func TestFormTokenOtherSession(t *testing.T) {
+	token, _ := issueFormToken(t, "checkout")
+	_, other := issueFormToken(t, "checkout")
+
+	assert.False(t, newFormTokenKit(other).VerifyFormToken("checkout", token))
+	assert.False(t, newFormTokenKit(nil).VerifyFormToken("checkout", token))
+}
+
+func TestFormTokenExpired(t *testing.T) {
+	ttl := FormTokenTTL
+	FormTokenTTL = -time.Second
+	defer func() { FormTokenTTL = ttl }()
+
+	token, cookies := issueFormToken(t, "checkout")
+	assert.False(t, newFormTokenKit(cookies).VerifyFormToken("checkout", token))
+}
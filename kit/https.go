@@ -0,0 +1,56 @@
+package kit
+
+import (
+	"net"
+	"net/http"
+)
+
+// WithHTTPSRedirect redirects plain HTTP requests to their HTTPS
+// equivalent. The scheme is detected with Kit.Scheme, hence requests from
+// trusted proxies setting X-Forwarded-Proto are handled correctly. Requests
+// for the skipped paths (e.g. health checks) and localhost requests in
+// development are passed through.
+//
+//	router.Use(kit.WithHTTPSRedirect("/health"))
+func WithHTTPSRedirect(skip ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			kit := &Kit{
+				Response: w,
+				Request:  r,
+			}
+			if kit.Scheme() == "https" || isSkippedPath(r.URL.Path, skip) ||
+				(IsDevelopment() && isLocalhost(kit.host())) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Use 308 for methods other than GET and HEAD so clients
+			// will not change the method when following the redirect.
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+			http.Redirect(w, r, "https://"+kit.host()+r.URL.RequestURI(), status)
+		})
+	}
+}
+
+func isSkippedPath(path string, skip []string) bool {
+	for _, p := range skip {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+func isLocalhost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,63 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+})
+
+func TestWithHTTPSRedirect(t *testing.T) {
+	h := WithHTTPSRedirect("/health")(okHandler)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/users?page=2", nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "https://example.com/users?page=2", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "http://example.com/users", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWithHTTPSRedirectProxied(t *testing.T) {
+	assert.Nil(t, SetTrustedProxies("10.0.0.1"))
+	defer SetTrustedProxies()
+	h := WithHTTPSRedirect()(okHandler)
+
+	r := httptest.NewRequest("GET", "http://internal/users", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	r.Header.Set("X-Forwarded-Proto", "http")
+	r.Header.Set("X-Forwarded-Host", "example.com")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "https://example.com/users", w.Header().Get("Location"))
+}
+
+func TestWithHTTPSRedirectDevelopment(t *testing.T) {
+	t.Setenv("SUPERKIT_ENV", "development")
+	h := WithHTTPSRedirect()(okHandler)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:3000/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+}
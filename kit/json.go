@@ -0,0 +1,108 @@
+package kit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// JSONConfig configures how the JSON helpers encode values.
+type JSONConfig struct {
+	// EscapeHTML escapes <, > and & in JSON strings, which is only
+	// needed when embedding JSON in HTML.
+	EscapeHTML bool
+	// Indent, if set, is used to indent the output of Kit.JSON.
+	Indent string
+}
+
+// JSONEncoderConfig is the JSONConfig used by Kit.JSON and the JSON
+// streaming helpers.
+var JSONEncoderConfig = JSONConfig{
+	EscapeHTML: false,
+}
+
+func newJSONEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(JSONEncoderConfig.EscapeHTML)
+	if len(JSONEncoderConfig.Indent) > 0 {
+		enc.SetIndent("", JSONEncoderConfig.Indent)
+	}
+	return enc
+}
+
+// marshalJSON is like json.Marshal, but respects the EscapeHTML setting
+// of the JSONEncoderConfig.
+func marshalJSON(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(JSONEncoderConfig.EscapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Data writes the given payload wrapped in a {"data": ...} envelope, so
+// all successful API responses share the same shape.
+//
+//	return kit.Data(http.StatusOK, users)
+func (kit *Kit) Data(status int, data any) error {
+	return kit.JSON(status, map[string]any{"data": data})
+}
+
+// Errors writes the given errors wrapped in a {"errors": [...]} envelope,
+// so all failed API responses share the same shape.
+//
+//	return kit.Errors(http.StatusUnprocessableEntity, *kit.ErrUnprocessableEntity.WithCode("invalid_email"))
+func (kit *Kit) Errors(status int, errs ...APIError) error {
+	if errs == nil {
+		errs = []APIError{}
+	}
+	return kit.JSON(status, map[string]any{"errors": errs})
+}
+
+// JSONCached is like JSON, but sets a weak ETag computed from the encoded
+// value and responds with 304 Not Modified when the If-None-Match header
+// of the request matches, saving bandwidth for polling clients. Only 2xx
+// responses are conditional.
+//
+//	return kit.JSONCached(http.StatusOK, notifications)
+func (kit *Kit) JSONCached(status int, v any) error {
+	if kit.aborted {
+		return nil
+	}
+	b, err := marshalJSON(v)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return kit.write(status, "application/json", b)
+	}
+	sum := sha256.Sum256(b)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	kit.Response.Header().Set("ETag", etag)
+	if etagMatches(kit.Request.Header.Get("If-None-Match"), etag) {
+		kit.Response.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	return kit.write(status, "application/json", b)
+}
+
+// etagMatches reports whether the If-None-Match header matches the given
+// ETag using the weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONEscapeHTML(t *testing.T) {
+	defer func(config JSONConfig) { JSONEncoderConfig = config }(JSONEncoderConfig)
+	data := map[string]string{"html": "<b>foo & bar</b>"}
+
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.JSON(http.StatusOK, data))
+	assert.Equal(t, `{"html":"<b>foo & bar</b>"}`+"\n", w.Body.String())
+
+	JSONEncoderConfig.EscapeHTML = true
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.JSON(http.StatusOK, data))
+	assert.Equal(t, `{"html":"\u003cb\u003efoo \u0026 bar\u003c/b\u003e"}`+"\n", w.Body.String())
+}
+
+func TestJSONStreamEscapeHTML(t *testing.T) {
+	items := make(chan any, 1)
+	items <- "<b>"
+	close(items)
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.JSONStream(http.StatusOK, items))
+	assert.Equal(t, `["<b>"]`+"\n", w.Body.String())
+}
+
+func TestJSONIndent(t *testing.T) {
+	defer func(config JSONConfig) { JSONEncoderConfig = config }(JSONEncoderConfig)
+	JSONEncoderConfig.Indent = "  "
+
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.JSON(http.StatusOK, map[string]int{"id": 1}))
+	assert.Equal(t, "{\n  \"id\": 1\n}\n", w.Body.String())
+}
+
+func TestData(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Data(http.StatusCreated, map[string]int{"id": 1}))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"data": {"id": 1}}`, w.Body.String())
+}
+
+func TestErrors(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	err := kit.Errors(http.StatusUnprocessableEntity,
+		*ErrUnprocessableEntity.WithCode("invalid_email"),
+		*NewAPIError(http.StatusUnprocessableEntity, "name is required"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"errors": [
+		{"status": 422, "message": "Unprocessable Entity", "code": "invalid_email"},
+		{"status": 422, "message": "name is required"}
+	]}`, w.Body.String())
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Errors(http.StatusBadRequest))
+	assert.JSONEq(t, `{"errors": []}`, w.Body.String())
+}
+
+func TestJSONCached(t *testing.T) {
+	data := map[string]any{"unread": 3}
+	kit, w := newTestKit(httptest.NewRequest("GET", "/notifications", nil))
+	assert.Nil(t, kit.JSONCached(http.StatusOK, data))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"unread":3}`, w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+
+	r := httptest.NewRequest("GET", "/notifications", nil)
+	r.Header.Set("If-None-Match", `"other", `+etag)
+	kit, w = newTestKit(r)
+	assert.Nil(t, kit.JSONCached(http.StatusOK, data))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	r = httptest.NewRequest("GET", "/notifications", nil)
+	r.Header.Set("If-None-Match", etag)
+	kit, w = newTestKit(r)
+	assert.Nil(t, kit.JSONCached(http.StatusOK, map[string]any{"unread": 4}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
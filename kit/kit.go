@@ -3,10 +3,15 @@ package kit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/gorilla/sessions"
@@ -18,18 +23,29 @@ type HandlerFunc func(kit *Kit) error
 
 type ErrorHandlerFunc func(kit *Kit, err error)
 
-type AuthKey struct{}
-
 type Auth interface {
 	Check() bool
 }
 
+// TimeLayouts are the layouts QueryTime and FormTime try in order
+// when no explicit layout is given.
+var TimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"15:04",
+}
+
 var (
-	errorHandler = func(kit *Kit, err error) {
-		kit.Text(http.StatusInternalServerError, err.Error())
-	}
+	errorHandler ErrorHandlerFunc = DefaultErrorHandler
 )
 
+// Identity can optionally be implemented by an Auth to expose the ID of
+// the authenticated user. See Kit.UserID.
+type Identity interface {
+	ID() string
+}
+
 type DefaultAuth struct{}
 
 func (DefaultAuth) Check() bool { return false }
@@ -37,19 +53,107 @@ func (DefaultAuth) Check() bool { return false }
 type Kit struct {
 	Response http.ResponseWriter
 	Request  *http.Request
+
+	renderCtx     context.Context
+	locals        map[string]any
+	rawBody       []byte
+	bodyCached    bool
+	streamingBody bool
+	cspNonce      string
+	jsonValues    map[string]json.RawMessage
+	aborted       bool
+}
+
+// kitFor returns the Kit of the request, creating it on first use. The
+// Kit is stored in the context of its Request, so middleware and the
+// final handler share a single Kit and the locals set with Kit.Set flow
+// through. Middleware must pass the Request of the returned Kit, or one
+// derived from it, to the next handler.
+func kitFor(w http.ResponseWriter, r *http.Request) *Kit {
+	if kit, ok := r.Context().Value(kitKey).(*Kit); ok {
+		if kit.Request != r {
+			// The context may carry new values, like the Auth.
+			kit.renderCtx = nil
+		}
+		kit.Response = w
+		kit.Request = r
+		return kit
+	}
+	kit := &Kit{Response: w}
+	kit.Request = r.WithContext(WithContextValue(r.Context(), kitKey, kit))
+	return kit
+}
+
+// derive returns a copy of the Kit for a handler running in another
+// goroutine, like one running with a timeout, so the handler never changes
+// the Kit the middleware see. The copy is stored in the given context,
+// which becomes the context of its Request.
+func (kit *Kit) derive(w http.ResponseWriter, ctx context.Context) *Kit {
+	inner := &Kit{
+		Response:      w,
+		renderCtx:     kit.renderCtx,
+		locals:        maps.Clone(kit.locals),
+		rawBody:       kit.rawBody,
+		bodyCached:    kit.bodyCached,
+		streamingBody: kit.streamingBody,
+		cspNonce:      kit.cspNonce,
+		jsonValues:    kit.jsonValues,
+		aborted:       kit.aborted,
+	}
+	inner.Request = kit.Request.WithContext(WithContextValue(ctx, kitKey, inner))
+	return inner
 }
 
 func UseErrorHandler(h ErrorHandlerFunc) { errorHandler = h }
 
+// Set stores a value in the locals of the Kit.
+func (kit *Kit) Set(key string, value any) {
+	if kit.locals == nil {
+		kit.locals = make(map[string]any)
+	}
+	kit.locals[key] = value
+}
+
+// Get returns the value stored in the locals of the Kit under
+// the given key, or nil if not present.
+func (kit *Kit) Get(key string) any {
+	return kit.locals[key]
+}
+
+// Auth returns the Auth of the current request. A request that passed
+// the authentication middleware without credentials has a DefaultAuth,
+// which is not authenticated. If the middleware did not run at all a
+// warning is logged, since that usually means a route is missing it.
 func (kit *Kit) Auth() Auth {
-	value, ok := kit.Request.Context().Value(AuthKey{}).(Auth)
+	value, ok := AuthFromContext(kit.Request.Context())
 	if !ok {
-		slog.Warn("kit authentication not set")
+		defaultLogger().Warn("kit authentication not set", "path", kit.Request.URL.Path)
 		return DefaultAuth{}
 	}
 	return value
 }
 
+// HasAuth reports whether the authentication middleware ran for the
+// current request, regardless of whether the request is authenticated.
+func (kit *Kit) HasAuth() bool {
+	_, ok := AuthFromContext(kit.Request.Context())
+	return ok
+}
+
+// UserID returns the ID of the authenticated user if the current Auth
+// implements Identity and is authenticated.
+func (kit *Kit) UserID() (string, bool) {
+	auth, ok := AuthFromContext(kit.Request.Context())
+	if !ok || !auth.Check() {
+		return "", false
+	}
+	identity, ok := auth.(Identity)
+	if !ok {
+		return "", false
+	}
+	return identity.ID(), true
+}
+
 // GetSession return a session by its name. GetSession always
 // returns a session even if it does not exist.
 func (kit *Kit) GetSession(name string) *sessions.Session {
@@ -57,8 +161,19 @@ func (kit *Kit) GetSession(name string) *sessions.Session {
 	return sess
 }
 
+// Fail returns the given error as is. It exists for readability in
+// handlers returning one of the sentinel errors.
+//
+//	return kit.Fail(kit.ErrNotFound)
+func (kit *Kit) Fail(err error) error {
+	return err
+}
+
 // Redirect with HTMX support.
 func (kit *Kit) Redirect(status int, url string) error {
+	if kit.aborted {
+		return nil
+	}
 	if len(kit.Request.Header.Get("HX-Request")) > 0 {
 		kit.Response.Header().Set("HX-Redirect", url)
 		kit.Response.WriteHeader(http.StatusSeeOther)
@@ -68,81 +183,447 @@ func (kit *Kit) Redirect(status int, url string) error {
 	return nil
 }
 
+// ErrAborted is returned by Abort. Handler treats it as already handled.
+var ErrAborted = errors.New("request aborted")
+
+// Abort writes only the given status, e.g. a 204 or a 403 without body,
+// after which the write helpers no-op. It returns ErrAborted, which
+// Handler recognizes as already handled.
+//
+//	if !allowed {
+//		return kit.Abort(http.StatusForbidden)
+//	}
+func (kit *Kit) Abort(status int) error {
+	if !kit.aborted {
+		kit.Response.WriteHeader(status)
+		kit.aborted = true
+	}
+	return ErrAborted
+}
+
+// Header sets the given response header, replacing any existing values,
+// and returns the Kit for chaining.
+//
+//	kit.Header("Cache-Control", "no-store").JSON(http.StatusOK, data)
+func (kit *Kit) Header(key, value string) *Kit {
+	kit.Response.Header().Set(key, value)
+	return kit
+}
+
+// AddHeader adds the given value to the given response header
+// and returns the Kit for chaining.
+func (kit *Kit) AddHeader(key, value string) *Kit {
+	kit.Response.Header().Add(key, value)
+	return kit
+}
+
+// CacheControl allows the response to be cached for maxAge, either by
+// any cache (public) or only by the client (private).
+func (kit *Kit) CacheControl(maxAge time.Duration, public bool) {
+	visibility := "private"
+	if public {
+		visibility = "public"
+	}
+	kit.Response.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int(maxAge.Seconds())))
+}
+
+// NoCache prevents the response from being cached, which should be used
+// for pages showing user specific data.
+func (kit *Kit) NoCache() {
+	kit.Response.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
+	kit.Response.Header().Set("Pragma", "no-cache")
+	kit.Response.Header().Set("Expires", "0")
+}
+
 func (kit *Kit) FormValue(name string) string {
+	kit.parseForm()
 	return kit.Request.PostFormValue(name)
 }
 
+// FormValues returns all the values of the given form field, which is
+// useful for multi selects and checkbox groups sharing the same name.
+func (kit *Kit) FormValues(name string) []string {
+	kit.parseForm()
+	// PostFormValue takes care of parsing url encoded forms.
+	kit.Request.PostFormValue(name)
+	return kit.Request.PostForm[name]
+}
+
+// FormBool returns true if the given form field is present and its value
+// is not "false", "0" or "off". Unchecked checkboxes are not sent by the
+// browser, hence an absent field results in false.
+func (kit *Kit) FormBool(name string) bool {
+	values := kit.FormValues(name)
+	if len(values) == 0 {
+		return false
+	}
+	switch strings.ToLower(values[0]) {
+	case "false", "0", "off":
+		return false
+	}
+	return true
+}
+
+// QueryAll returns all the values of the given query parameter, which
+// is useful for repeated parameters like ?tag=a&tag=b.
+func (kit *Kit) QueryAll(key string) []string {
+	return kit.Request.URL.Query()[key]
+}
+
+// QueryCSV returns all the values of the given query parameter like
+// QueryAll, additionally splitting comma separated values.
+//
+//	?tag=a,b&tag=c // => [a b c]
+func (kit *Kit) QueryCSV(key string) []string {
+	values := []string{}
+	for _, value := range kit.QueryAll(key) {
+		for _, v := range strings.Split(value, ",") {
+			v = strings.TrimSpace(v)
+			if len(v) > 0 {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// QueryTime parses the given query parameter as time using the given
+// layout. If layout is empty the TimeLayouts are tried in order.
+func (kit *Kit) QueryTime(key, layout string) (time.Time, error) {
+	return parseTime(key, kit.Request.URL.Query().Get(key), layout)
+}
+
+// FormTime parses the given form field as time using the given
+// layout. If layout is empty the TimeLayouts are tried in order.
+func (kit *Kit) FormTime(name, layout string) (time.Time, error) {
+	return parseTime(name, kit.FormValue(name), layout)
+}
+
+func parseTime(name, value, layout string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, fmt.Errorf("missing time value for %s", name)
+	}
+	layouts := TimeLayouts
+	if len(layout) > 0 {
+		layouts = []string{layout}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time value (%s) for %s: expected layout %v", value, name, layouts)
+}
+
+// ContentLengthThreshold is the maximum size of a response body for which
+// the write helpers (JSON, Text, HTML and Bytes) set the Content-Length
+// header. Larger bodies are streamed to the client.
+var ContentLengthThreshold = 64 << 10
+
+// JSON writes the given value as JSON with the given status. Writing is
+// aborted with the context error if the request context is done, for
+// example when the client disconnected.
 func (kit *Kit) JSON(status int, v any) error {
-	kit.Response.WriteHeader(status)
+	if kit.aborted {
+		return nil
+	}
+	ctx := kit.Request.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	kit.Response.Header().Set("Content-Type", "application/json")
-	return json.NewEncoder(kit.Response).Encode(v)
+	w := newLengthWriter(kit.Response, status, ContentLengthThreshold)
+	if err := newJSONEncoder(contextWriter{ctx: ctx, w: w}).Encode(v); err != nil {
+		return err
+	}
+	return w.Close()
 }
 
+// Charset is the charset set in the Content-Type of Text, Textf and HTML
+// responses. If empty no charset is set.
+var Charset = "utf-8"
+
 func (kit *Kit) Text(status int, msg string) error {
-	kit.Response.WriteHeader(status)
-	kit.Response.Header().Set("Content-Type", "text/plain")
-	_, err := kit.Response.Write([]byte(msg))
-	return err
+	return kit.write(status, withCharset("text/plain"), []byte(msg))
+}
+
+// Textf writes the formatted message as plain text with the given status.
+func (kit *Kit) Textf(status int, format string, args ...any) error {
+	return kit.Text(status, fmt.Sprintf(format, args...))
+}
+
+// HTML writes the given HTML string with the given status.
+func (kit *Kit) HTML(status int, html string) error {
+	return kit.write(status, withCharset("text/html"), []byte(html))
+}
+
+func withCharset(contentType string) string {
+	if len(Charset) == 0 {
+		return contentType
+	}
+	return contentType + "; charset=" + Charset
 }
 
 func (kit *Kit) Bytes(status int, b []byte) error {
+	return kit.write(status, "text/plain", b)
+}
+
+func (kit *Kit) write(status int, contentType string, b []byte) error {
+	if kit.aborted {
+		return nil
+	}
+	kit.Response.Header().Set("Content-Type", contentType)
+	if len(b) <= ContentLengthThreshold {
+		kit.Response.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	}
 	kit.Response.WriteHeader(status)
-	kit.Response.Header().Set("Content-Type", "text/plain")
 	_, err := kit.Response.Write(b)
 	return err
 }
 
+var (
+	// RenderContentType is the Content-Type Render sets unless the
+	// handler already set one, e.g. image/svg+xml for SVG components.
+	RenderContentType = "text/html; charset=utf-8"
+	// RenderDefaultStatus is the status Render responds with. Handlers
+	// writing their own status before rendering should keep the default.
+	RenderDefaultStatus = http.StatusOK
+)
+
+// Render renders the given component. The output is buffered and flushed
+// to the client periodically. Rendering is aborted with the context error
+// if the request context is done, for example when the client disconnected.
+// The response defaults to RenderContentType and RenderDefaultStatus.
 func (kit *Kit) Render(c templ.Component) error {
-	return c.Render(kit.Request.Context(), kit.Response)
+	if kit.aborted {
+		return nil
+	}
+	header := kit.Response.Header()
+	if len(header.Get("Content-Type")) == 0 && len(RenderContentType) > 0 {
+		header.Set("Content-Type", RenderContentType)
+	}
+	// The render context saves the flash session, which must happen
+	// before the status is written.
+	ctx := kit.RenderContext()
+	if RenderDefaultStatus != http.StatusOK {
+		// The status is otherwise written by the first write.
+		kit.Response.WriteHeader(RenderDefaultStatus)
+	}
+	w := newRenderWriter(ctx, kit.Response)
+	if err := c.Render(ctx, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Deadline returns the deadline of the request context, e.g. set by
+// WithTimeout, and whether there is one.
+func (kit *Kit) Deadline() (time.Time, bool) {
+	return kit.Request.Context().Deadline()
+}
+
+// RemainingTime returns the time left until the deadline of the request
+// context, or zero if it already passed. Without a deadline the maximum
+// duration is returned. Handlers can use it to bail out early instead of
+// starting an expensive operation that will not finish in time.
+//
+//	if kit.RemainingTime() < 2*time.Second {
+//		return kit.ErrServiceUnavailable
+//	}
+func (kit *Kit) RemainingTime() time.Duration {
+	deadline, ok := kit.Deadline()
+	if !ok {
+		return math.MaxInt64
+	}
+	return max(time.Until(deadline), 0)
 }
 
 func (kit *Kit) Getenv(name string, def string) string {
 	return Getenv(name, def)
 }
 
+// Handler adapts the given HandlerFunc to a http.HandlerFunc. Errors
+// returned by the handler are passed to the error handler, unless the
+// client disconnected, see IsClientDisconnect, and either the request
+// context was canceled or writing the response failed. For HEAD requests the
+// handler runs as usual, but only the status and headers are sent,
+// including the Content-Length of the discarded body.
 func Handler(h HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		kit := &Kit{
-			Response: w,
-			Request:  r,
-		}
-		if err := h(kit); err != nil {
-			if errorHandler != nil {
-				errorHandler(kit, err)
-				return
-			}
-			kit.Text(http.StatusInternalServerError, err.Error())
+		if r.Method == http.MethodHead {
+			hw := &headResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			// Not deferred, a panicking handler must leave the status to
+			// the recovery middleware.
+			serve(h, hw, r)
+			hw.finish()
+			return
 		}
+		serve(h, w, r)
+	}
+}
+
+// serve runs the handler, passing its error to the error handler.
+func serve(h HandlerFunc, w http.ResponseWriter, r *http.Request) {
+	fw := &failureWriter{ResponseWriter: w}
+	kit := kitFor(fw, r)
+	err := h(kit)
+	if err == nil || errors.Is(err, ErrAborted) {
+		return
+	}
+	// Errors wrapping context.Canceled or a broken pipe may just as well
+	// come from the handler's own contexts or downstream calls, in which
+	// case the client is still waiting for a response.
+	gone := errors.Is(kit.Request.Context().Err(), context.Canceled) || fw.failed
+	if gone && IsClientDisconnect(err) {
+		defaultLogger().Debug("client disconnected", "path", r.URL.Path, "err", err)
+		return
+	}
+	if errorHandler != nil {
+		errorHandler(kit, err)
+		return
+	}
+	kit.Text(http.StatusInternalServerError, err.Error())
+}
+
+// HandlerE is like Handler, but also returns a pointer to the error the
+// handler returned on its last invocation, so tests can assert on the
+// exact error instead of the response. It is not safe for concurrent
+// requests and intended for tests only.
+//
+//	h, err := kit.HandlerE(handleShowUser)
+//	h.ServeHTTP(w, r)
+//	assert.ErrorIs(t, *err, kit.ErrNotFound)
+func HandlerE(h HandlerFunc) (http.HandlerFunc, *error) {
+	var last error
+	return Handler(func(kit *Kit) error {
+		last = h(kit)
+		return last
+	}), &last
+}
+
+// With wraps the handler with the given middleware for a single route.
+// Middleware run in the declared order before the handler.
+//
+//	router.Handle("/admin", handleAdmin.With(requireAdmin))
+func (h HandlerFunc) With(mw ...func(http.Handler) http.Handler) http.HandlerFunc {
+	var handler http.Handler = Handler(h)
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
 	}
+	return handler.ServeHTTP
 }
 
+// ErrAuthHandled can be returned by an AuthFunc that already wrote a
+// response, e.g. a redirect to an OAuth provider. The authentication
+// middleware will stop processing the request without writing anything.
+var ErrAuthHandled = errors.New("authentication response already written")
+
 type AuthenticationConfig struct {
+	// AuthFunc returns the Auth of the request. Any returned error, except
+	// ErrAuthHandled, is passed to the error handler.
 	AuthFunc    func(*Kit) (Auth, error)
 	RedirectURL string
+	// APIPrefixes are the path prefixes of API routes. In strict mode,
+	// unauthenticated requests for these routes, or requests accepting
+	// JSON but not HTML, are passed to the error handler with
+	// ErrUnauthorized instead of being redirected. HTMX requests are
+	// redirected with the HX-Redirect header.
+	APIPrefixes []string
+	// RememberFunc, if set, returns the Auth of the user of a valid
+	// remember me token when AuthFunc did not authenticate the request.
+	// See Kit.Remember.
+	RememberFunc func(kit *Kit, userID string) (Auth, error)
 }
 
 func WithAuthentication(config AuthenticationConfig, strict bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			kit := &Kit{
-				Response: w,
-				Request:  r,
-			}
+			kit := kitFor(w, r)
 			auth, err := config.AuthFunc(kit)
+			if errors.Is(err, ErrAuthHandled) {
+				return
+			}
+			if err != nil {
+				errorHandler(kit, err)
+				return
+			}
+			if auth == nil {
+				auth = DefaultAuth{}
+			}
+			auth, err = rememberAuth(kit, config, auth)
 			if err != nil {
 				errorHandler(kit, err)
 				return
 			}
 			if strict && !auth.Check() && r.URL.Path != config.RedirectURL {
+				if isAPIRequest(r, config.APIPrefixes) {
+					errorHandler(kit, ErrUnauthorized)
+					return
+				}
 				kit.Redirect(http.StatusSeeOther, config.RedirectURL)
 				return
 			}
-			ctx := context.WithValue(r.Context(), AuthKey{}, auth)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			ctx := WithAuth(kit.Request.Context(), auth)
+			next.ServeHTTP(w, kit.Request.WithContext(ctx))
 		})
 	}
 }
 
+// isAPIRequest reports whether the request is made by an API client rather
+// than a browser, based on its path or its Accept header.
+func isAPIRequest(r *http.Request, prefixes []string) bool {
+	if len(r.Header.Get("HX-Request")) > 0 {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// RequireAuth is the strict authentication middleware. Requests that are
+// not authenticated will be redirected to the configured RedirectURL.
+func RequireAuth(config AuthenticationConfig) func(http.Handler) http.Handler {
+	return WithAuthentication(config, true)
+}
+
+// OptionalAuth loads the Auth of the request if present, but never blocks
+// requests that are not authenticated.
+func OptionalAuth(config AuthenticationConfig) func(http.Handler) http.Handler {
+	return WithAuthentication(config, false)
+}
+
+// MultiAuth returns an AuthFunc trying the given strategies in order,
+// e.g. a session for the browser and a token for the API. The first Auth
+// that is authenticated is returned, otherwise the Auth of the last
+// strategy. Errors returned by a strategy stop the chain.
+//
+//	kit.RequireAuth(kit.AuthenticationConfig{
+//		AuthFunc: kit.MultiAuth(sessionAuth, tokenAuth),
+//	})
+func MultiAuth(strategies ...func(*Kit) (Auth, error)) func(*Kit) (Auth, error) {
+	return func(kit *Kit) (Auth, error) {
+		var auth Auth = DefaultAuth{}
+		for _, strategy := range strategies {
+			a, err := strategy(kit)
+			if err != nil {
+				return nil, err
+			}
+			if a == nil {
+				a = DefaultAuth{}
+			}
+			if a.Check() {
+				return a, nil
+			}
+			auth = a
+		}
+		return auth, nil
+	}
+}
+
 func Getenv(name string, def string) string {
 	env := os.Getenv(name)
 	if len(env) == 0 {
@@ -159,6 +640,35 @@ func IsProduction() bool {
 	return os.Getenv("SUPERKIT_ENV") == "production"
 }
 
+// DevOnly applies the given middleware only in development. In other
+// environments requests bypass it.
+//
+//	router.Use(kit.DevOnly(dumpRequests))
+func DevOnly(mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return onlyIf(IsDevelopment, mw)
+}
+
+// ProdOnly applies the given middleware only in production. In other
+// environments requests bypass it.
+//
+//	router.Use(kit.ProdOnly(withHSTS))
+func ProdOnly(mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return onlyIf(IsProduction, mw)
+}
+
+func onlyIf(cond func() bool, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cond() {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func Env() string {
 	return os.Getenv("SUPERKIT_ENV")
 }
@@ -0,0 +1,364 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The session store is initialized in init(), which requires a valid
+// SUPERKIT_SECRET. Package level variables are initialized before any
+// init function runs, hence we can set it here.
+var _ = os.Setenv("SUPERKIT_SECRET", "test-secret-that-is-at-least-32-bytes-long")
+
+func newTestKit(r *http.Request) (*Kit, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	return &Kit{Response: w, Request: r}, w
+}
+
+func TestQueryAll(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/?tag=a&tag=b", nil))
+	assert.Equal(t, []string{"a", "b"}, kit.QueryAll("tag"))
+	assert.Empty(t, kit.QueryAll("foo"))
+}
+
+func TestQueryCSV(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/?tag=a,b&tag=c&tag=", nil))
+	assert.Equal(t, []string{"a", "b", "c"}, kit.QueryCSV("tag"))
+	assert.Empty(t, kit.QueryCSV("foo"))
+}
+
+func newFormRequest(form string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestFormBool(t *testing.T) {
+	kit, _ := newTestKit(newFormRequest("remember=on&name=foo"))
+	assert.True(t, kit.FormBool("remember"))
+	assert.True(t, kit.FormBool("name"))
+
+	kit, _ = newTestKit(newFormRequest("name=foo"))
+	assert.False(t, kit.FormBool("remember"))
+
+	kit, _ = newTestKit(newFormRequest("remember=off"))
+	assert.False(t, kit.FormBool("remember"))
+}
+
+func TestFormValues(t *testing.T) {
+	kit, _ := newTestKit(newFormRequest("color=red&color=blue"))
+	assert.Equal(t, []string{"red", "blue"}, kit.FormValues("color"))
+	assert.Empty(t, kit.FormValues("size"))
+}
+
+func TestQueryTime(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/?date=2024-06-10&ts=2024-06-10T16:10:57Z&bad=10/06/2024", nil))
+
+	date, err := kit.QueryTime("date", "")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), date)
+
+	ts, err := kit.QueryTime("ts", time.RFC3339)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 6, 10, 16, 10, 57, 0, time.UTC), ts)
+
+	_, err = kit.QueryTime("bad", "")
+	assert.NotNil(t, err)
+	_, err = kit.QueryTime("date", time.RFC3339)
+	assert.NotNil(t, err)
+}
+
+func TestFormTime(t *testing.T) {
+	kit, _ := newTestKit(newFormRequest("birthday=1990-02-01"))
+	birthday, err := kit.FormTime("birthday", "2006-01-02")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(1990, 2, 1, 0, 0, 0, 0, time.UTC), birthday)
+
+	_, err = kit.FormTime("missing", "")
+	assert.NotNil(t, err)
+}
+
+func TestHeader(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	err := kit.
+		Header("Cache-Control", "no-store").
+		AddHeader("Vary", "Accept").
+		AddHeader("Vary", "HX-Request").
+		JSON(http.StatusOK, map[string]string{"foo": "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{"Accept", "HX-Request"}, w.Header().Values("Vary"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+// cancelingValue cancels the context while being encoded.
+type cancelingValue struct {
+	cancel context.CancelFunc
+}
+
+func (v cancelingValue) MarshalJSON() ([]byte, error) {
+	v.cancel()
+	return []byte(`"foo"`), nil
+}
+
+func TestJSONContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	err := kit.JSON(http.StatusOK, []any{cancelingValue{cancel}})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, w.Body.String())
+
+	err = kit.JSON(http.StatusOK, "foo")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+type testIdentity struct {
+	id string
+}
+
+func (i testIdentity) Check() bool { return len(i.id) > 0 }
+
+func (i testIdentity) ID() string { return i.id }
+
+func TestUserID(t *testing.T) {
+	withAuth := func(auth Auth) *Kit {
+		r := httptest.NewRequest("GET", "/", nil)
+		kit, _ := newTestKit(r.WithContext(WithAuth(r.Context(), auth)))
+		return kit
+	}
+
+	id, ok := withAuth(testIdentity{id: "42"}).UserID()
+	assert.True(t, ok)
+	assert.Equal(t, "42", id)
+
+	_, ok = withAuth(testIdentity{}).UserID()
+	assert.False(t, ok)
+
+	_, ok = withAuth(testAuth{ID: 1}).UserID()
+	assert.False(t, ok)
+
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	_, ok = kit.UserID()
+	assert.False(t, ok)
+}
+
+func TestHandlerFuncWith(t *testing.T) {
+	calls := []string{}
+	middleware := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	handler := HandlerFunc(func(kit *Kit) error {
+		calls = append(calls, "handler")
+		return kit.Text(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	handler.With(middleware("first"), middleware("second")).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+	assert.Equal(t, "ok", w.Body.String())
+}
+
+func TestHandlerHead(t *testing.T) {
+	h := Handler(func(kit *Kit) error {
+		return kit.Header("X-Foo", "bar").JSON(http.StatusAccepted, map[string]string{"foo": "bar"})
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("HEAD", "/", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "bar", w.Header().Get("X-Foo"))
+	assert.Equal(t, strconv.Itoa(len(body)), w.Header().Get("Content-Length"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestContentLength(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.JSON(http.StatusOK, map[string]string{"foo": "bar"}))
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Text(http.StatusOK, "hello"))
+	assert.Equal(t, "5", w.Header().Get("Content-Length"))
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.HTML(http.StatusOK, "<h1>hello</h1>"))
+	assert.Equal(t, "14", w.Header().Get("Content-Length"))
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestContentLengthLargeBody(t *testing.T) {
+	defer func(threshold int) { ContentLengthThreshold = threshold }(ContentLengthThreshold)
+	ContentLengthThreshold = 16
+
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.JSON(http.StatusCreated, strings.Repeat("a", 32)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Length"))
+	assert.Equal(t, `"`+strings.Repeat("a", 32)+`"`+"\n", w.Body.String())
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Bytes(http.StatusOK, make([]byte, 32)))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+}
+
+func TestCharset(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Textf(http.StatusOK, "hello %s", "wörld"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "hello wörld", w.Body.String())
+
+	defer func(charset string) { Charset = charset }(Charset)
+	Charset = "iso-8859-1"
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.HTML(http.StatusOK, "<h1>hello</h1>"))
+	assert.Equal(t, "text/html; charset=iso-8859-1", w.Header().Get("Content-Type"))
+
+	Charset = ""
+	kit, w = newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Text(http.StatusOK, "hello"))
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+}
+
+func TestCacheControl(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	kit.CacheControl(time.Hour, true)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+	kit.CacheControl(time.Minute, false)
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+}
+
+func TestNoCache(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	kit.NoCache()
+	assert.Nil(t, kit.Text(http.StatusOK, "secret"))
+	assert.Equal(t, "no-store, no-cache, must-revalidate", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "no-cache", w.Header().Get("Pragma"))
+	assert.Equal(t, "0", w.Header().Get("Expires"))
+}
+
+func headerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Middleware", "true")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestDevOnly(t *testing.T) {
+	h := DevOnly(headerMiddleware)(okHandler)
+
+	t.Setenv("SUPERKIT_ENV", "development")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "true", w.Header().Get("X-Middleware"))
+
+	t.Setenv("SUPERKIT_ENV", "production")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, w.Header().Get("X-Middleware"))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestProdOnly(t *testing.T) {
+	h := ProdOnly(headerMiddleware)(okHandler)
+
+	t.Setenv("SUPERKIT_ENV", "production")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "true", w.Header().Get("X-Middleware"))
+
+	t.Setenv("SUPERKIT_ENV", "development")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, w.Header().Get("X-Middleware"))
+}
+
+func TestHandlerE(t *testing.T) {
+	h, err := HandlerE(func(kit *Kit) error {
+		if kit.Request.URL.Query().Has("missing") {
+			return ErrNotFound.WithCode("user_not_found")
+		}
+		return kit.Text(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.ErrorIs(t, *err, ErrNotFound)
+	assert.Equal(t, "user_not_found", (*err).(*APIError).Code)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, *err)
+}
+
+func TestAbort(t *testing.T) {
+	var jsonErr error
+	h, err := HandlerE(func(kit *Kit) error {
+		abortErr := kit.Abort(http.StatusForbidden)
+		jsonErr = kit.JSON(http.StatusOK, map[string]string{"foo": "bar"})
+		return abortErr
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Type"))
+	assert.Nil(t, jsonErr)
+	assert.Equal(t, ErrAborted, *err)
+}
+
+func TestAbortStreamingHelpers(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "report.txt"), []byte("report"), 0o644))
+	writers := map[string]func(kit *Kit) error{
+		"JSONStream": func(kit *Kit) error {
+			items := make(chan any, 1)
+			items <- "foo"
+			close(items)
+			return kit.JSONStream(http.StatusOK, items)
+		},
+		"SSE": func(kit *Kit) error {
+			events := make(chan SSEEvent)
+			close(events)
+			return kit.SSE(events)
+		},
+		"File": func(kit *Kit) error {
+			return kit.File(dir, "report.txt")
+		},
+	}
+	for name, write := range writers {
+		t.Run(name, func(t *testing.T) {
+			var writeErr error
+			h := Handler(func(kit *Kit) error {
+				abortErr := kit.Abort(http.StatusNoContent)
+				writeErr = write(kit)
+				return abortErr
+			})
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Empty(t, w.Body.String())
+			assert.Empty(t, w.Header().Get("Content-Type"))
+			assert.Nil(t, writeErr)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package kit
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocaleFormat describes how numbers and dates are formatted in a locale.
+type LocaleFormat struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+	// DateLayout is the time layout of a date, e.g. 02.01.2006.
+	DateLayout string
+}
+
+var (
+	fallbackLocale = "en"
+	localeFormats  = map[string]LocaleFormat{
+		"en":    {DecimalSeparator: ".", ThousandsSeparator: ",", DateLayout: "01/02/2006"},
+		"en-gb": {DecimalSeparator: ".", ThousandsSeparator: ",", DateLayout: "02/01/2006"},
+		"de":    {DecimalSeparator: ",", ThousandsSeparator: ".", DateLayout: "02.01.2006"},
+		"es":    {DecimalSeparator: ",", ThousandsSeparator: ".", DateLayout: "02/01/2006"},
+		"fr":    {DecimalSeparator: ",", ThousandsSeparator: "\u202f", DateLayout: "02/01/2006"},
+		"it":    {DecimalSeparator: ",", ThousandsSeparator: ".", DateLayout: "02/01/2006"},
+		"nl":    {DecimalSeparator: ",", ThousandsSeparator: ".", DateLayout: "02-01-2006"},
+		"pt":    {DecimalSeparator: ",", ThousandsSeparator: ".", DateLayout: "02/01/2006"},
+	}
+)
+
+// SetFallbackLocale sets the locale used when none of the locales the
+// client accepts is registered, which must be a registered locale.
+// Defaults to en.
+func SetFallbackLocale(locale string) { fallbackLocale = strings.ToLower(locale) }
+
+// RegisterLocale registers or overrides the format of the given locale,
+// e.g. de-CH or sv.
+func RegisterLocale(locale string, format LocaleFormat) {
+	localeFormats[strings.ToLower(locale)] = format
+}
+
+// Locale returns the registered locale the client prefers most according
+// to its Accept-Language header, or the fallback locale. A regional
+// locale like de-AT falls back to its language if not registered.
+func (kit *Kit) Locale() string {
+	for _, tag := range parseAcceptLanguage(kit.Request.Header.Get("Accept-Language")) {
+		if _, ok := localeFormats[tag]; ok {
+			return tag
+		}
+		if lang, _, ok := strings.Cut(tag, "-"); ok {
+			if _, ok := localeFormats[lang]; ok {
+				return lang
+			}
+		}
+	}
+	return fallbackLocale
+}
+
+// FormatNumber formats the number according to the locale of the request.
+//
+//	kit.FormatNumber(1234.5) // => 1,234.5 or 1.234,5
+func (kit *Kit) FormatNumber(n float64) string {
+	return FormatNumber(kit.Locale(), n)
+}
+
+// FormatDate formats the date according to the locale of the request.
+//
+//	kit.FormatDate(t) // => 12/31/2024 or 31.12.2024
+func (kit *Kit) FormatDate(t time.Time) string {
+	return FormatDate(kit.Locale(), t)
+}
+
+// FormatNumber formats the number according to the given locale. Unknown
+// locales are formatted according to the fallback locale.
+func FormatNumber(locale string, n float64) string {
+	format := localeFormat(locale)
+	s := strconv.FormatFloat(n, 'f', -1, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(format.ThousandsSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString(format.DecimalSeparator)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// FormatDate formats the date according to the given locale. Unknown
+// locales are formatted according to the fallback locale.
+func FormatDate(locale string, t time.Time) string {
+	return t.Format(localeFormat(locale).DateLayout)
+}
+
+func localeFormat(locale string) LocaleFormat {
+	if format, ok := localeFormats[strings.ToLower(locale)]; ok {
+		return format
+	}
+	return localeFormats[fallbackLocale]
+}
+
+// parseAcceptLanguage returns the lower cased language tags of the given
+// Accept-Language header ordered by their quality values.
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if len(tag) == 0 || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				q = v
+			}
+		}
+		if q > 0 {
+			languages = append(languages, language{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].q > languages[j].q })
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
@@ -0,0 +1,56 @@
+package kit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLocaleKit(acceptLanguage string) *Kit {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", acceptLanguage)
+	kit, _ := newTestKit(r)
+	return kit
+}
+
+func TestLocale(t *testing.T) {
+	assert.Equal(t, "de", newLocaleKit("de-AT, en;q=0.8").Locale())
+	assert.Equal(t, "en-gb", newLocaleKit("en-GB").Locale())
+	assert.Equal(t, "nl", newLocaleKit("sv;q=0.9, nl;q=0.95").Locale())
+	assert.Equal(t, "en", newLocaleKit("sv").Locale())
+	assert.Equal(t, "en", newLocaleKit("").Locale())
+
+	defer SetFallbackLocale("en")
+	SetFallbackLocale("de")
+	assert.Equal(t, "de", newLocaleKit("sv").Locale())
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "1,234.5", newLocaleKit("en-US").FormatNumber(1234.5))
+	assert.Equal(t, "1.234,5", newLocaleKit("de-DE").FormatNumber(1234.5))
+	assert.Equal(t, "-1,234,567", FormatNumber("en", -1234567))
+	assert.Equal(t, "123", FormatNumber("en", 123))
+	assert.Equal(t, "0,25", FormatNumber("de", 0.25))
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "12/31/2024", newLocaleKit("en").FormatDate(date))
+	assert.Equal(t, "31.12.2024", newLocaleKit("de").FormatDate(date))
+	assert.Equal(t, "31/12/2024", newLocaleKit("en-GB").FormatDate(date))
+}
+
+func TestRegisterLocale(t *testing.T) {
+	defer delete(localeFormats, "sv")
+	RegisterLocale("sv", LocaleFormat{DecimalSeparator: ",", ThousandsSeparator: " ", DateLayout: "2006-01-02"})
+	kit := newLocaleKit("sv-SE")
+	assert.Equal(t, "1 234,5", kit.FormatNumber(1234.5))
+	assert.Equal(t, "2024-12-31", kit.FormatDate(time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestRenderContextLocale(t *testing.T) {
+	kit := newLocaleKit("de")
+	assert.Equal(t, "de", kit.RenderContext().Value(localeKey))
+}
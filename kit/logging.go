@@ -0,0 +1,265 @@
+package kit
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader is the header holding the ID of the request.
+const RequestIDHeader = "X-Request-ID"
+
+var appLogger *slog.Logger
+
+// SetLogger sets the default logger of the application, which is used by
+// Kit.Logger and the logging middleware. Defaults to slog.Default().
+func SetLogger(logger *slog.Logger) { appLogger = logger }
+
+func defaultLogger() *slog.Logger {
+	if appLogger != nil {
+		return appLogger
+	}
+	return slog.Default()
+}
+
+// Logger returns the default logger with the request ID, method, path
+// and, if authenticated, the user ID of the current request attached,
+// so the records of a handler can be correlated.
+//
+//	kit.Logger().Info("user created", "email", user.Email)
+func (kit *Kit) Logger() *slog.Logger {
+	attrs := []any{
+		"request_id", kit.RequestID(),
+		"method", kit.Request.Method,
+		"path", kit.Request.URL.Path,
+	}
+	if userID, ok := kit.UserID(); ok {
+		attrs = append(attrs, "user_id", userID)
+	}
+	return defaultLogger().With(attrs...)
+}
+
+// LoggingConfig is the configuration of the logging middleware.
+type LoggingConfig struct {
+	// Logger is used to log the requests, defaults to the logger set
+	// with SetLogger.
+	Logger *slog.Logger
+	// Sampler, if set, decides which successful (2xx) requests are
+	// logged. Other requests are always logged. The access log is not
+	// sampled.
+	Sampler *Sampler
+	// AccessLog, if set, receives a line in the AccessLogFormat for
+	// every request, for tools parsing standard web server logs.
+	AccessLog io.Writer
+	// AccessLogFormat is the format of the access log, defaults to
+	// CommonLogFormat.
+	AccessLogFormat AccessLogFormat
+	// DisableStructured disables the structured log records, which is
+	// useful when only the access log is needed.
+	DisableStructured bool
+}
+
+// AccessLogFormat is the format of the access log lines.
+type AccessLogFormat int
+
+const (
+	// CommonLogFormat is the Apache Common Log Format:
+	//	127.0.0.1 - 42 [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326
+	CommonLogFormat AccessLogFormat = iota
+	// CombinedLogFormat is the Common Log Format followed by the quoted
+	// Referer and User-Agent of the request.
+	CombinedLogFormat
+)
+
+// Sampler samples a fraction of the requests based on the hash of their
+// request ID, hence the decision is consistent for related log records.
+type Sampler struct {
+	// Rate is the fraction of requests that will be sampled, between 0 and 1.
+	Rate float64
+}
+
+// Sample returns true if the request with the given ID should be sampled.
+// Requests without an ID are sampled randomly.
+func (s *Sampler) Sample(requestID string) bool {
+	if s.Rate >= 1 {
+		return true
+	}
+	if s.Rate <= 0 {
+		return false
+	}
+	if len(requestID) == 0 {
+		return rand.Float64() < s.Rate
+	}
+	h := fnv.New32a()
+	h.Write([]byte(requestID))
+	return float64(h.Sum32())/math.MaxUint32 < s.Rate
+}
+
+// StatusRecorder is a http.ResponseWriter recording the status and the
+// number of body bytes written to the response.
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int64
+
+	wroteHeader bool
+}
+
+// NewStatusRecorder returns a StatusRecorder wrapping the given writer.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{
+		ResponseWriter: w,
+		Status:         http.StatusOK,
+	}
+}
+
+// WriteHeader records the status sent to the client, which is the first
+// one written. Later calls are superfluous and ignored by net/http, as are
+// informational 1xx statuses for the final status.
+func (rec *StatusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader && status >= 200 {
+		rec.Status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *StatusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.Bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the underlying writer so http.ResponseController
+// can access it.
+func (rec *StatusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.n += int64(n)
+	return n, err
+}
+
+// requestSize returns the size of the request body, based on the
+// Content-Length or the number of bytes the handler read.
+func requestSize(r *http.Request, body *countingReader) int64 {
+	if r.ContentLength >= 0 {
+		return r.ContentLength
+	}
+	return body.n
+}
+
+// accessLogMu serializes the writes to the access logs, which may not
+// be safe for concurrent use.
+var accessLogMu sync.Mutex
+
+// accessLogLine returns the access log line of the given request.
+func accessLogLine(kit *Kit, r *http.Request, rec *StatusRecorder, start time.Time, format AccessLogFormat) string {
+	user := "-"
+	if userID, ok := kit.UserID(); ok && len(userID) > 0 {
+		user = userID
+	}
+	size := "-"
+	if rec.Bytes > 0 {
+		size = strconv.FormatInt(rec.Bytes, 10)
+	}
+	uri := r.RequestURI
+	if len(uri) == 0 {
+		uri = r.URL.RequestURI()
+	}
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		kit.ClientIP(),
+		user,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method,
+		uri,
+		r.Proto,
+		rec.Status,
+		size,
+	)
+	if format == CombinedLogFormat {
+		line += fmt.Sprintf(" \"%s\" \"%s\"", escapeLogValue(r.Referer()), escapeLogValue(r.UserAgent()))
+	}
+	return line + "\n"
+}
+
+func escapeLogValue(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	quoted := strconv.Quote(s)
+	return quoted[1 : len(quoted)-1]
+}
+
+// WithLogging logs every request with its method, path, status, duration
+// and the sizes of the request and response bodies.
+//
+//	router.Use(kit.WithLogging(kit.LoggingConfig{
+//		Sampler: &kit.Sampler{Rate: 0.1},
+//	}))
+func WithLogging(config LoggingConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := NewStatusRecorder(w)
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+			// Share the Kit with the next handlers, so the access log can
+			// include the authenticated user.
+			kit := kitFor(rec, r)
+			next.ServeHTTP(rec, kit.Request)
+
+			if config.AccessLog != nil {
+				line := accessLogLine(kit, r, rec, start, config.AccessLogFormat)
+				accessLogMu.Lock()
+				io.WriteString(config.AccessLog, line)
+				accessLogMu.Unlock()
+			}
+			if config.DisableStructured {
+				return
+			}
+			success := rec.Status >= 200 && rec.Status < 300
+			if success && config.Sampler != nil && !config.Sampler.Sample(r.Header.Get(RequestIDHeader)) {
+				return
+			}
+			logger := config.Logger
+			if logger == nil {
+				logger = defaultLogger()
+			}
+			level := slog.LevelInfo
+			switch {
+			case rec.Status >= 500:
+				level = slog.LevelError
+			case rec.Status >= 400:
+				level = slog.LevelWarn
+			}
+			logger.Log(r.Context(), level, "http request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.Status,
+				"duration", time.Since(start),
+				"request_id", r.Header.Get(RequestIDHeader),
+				"request_size", requestSize(r, body),
+				"response_size", rec.Bytes,
+			)
+		})
+	}
+}
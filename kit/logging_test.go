@@ -0,0 +1,198 @@
+package kit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return slog.New(slog.NewJSONHandler(buf, nil)), buf
+}
+
+func serveLogged(h http.Handler, n int) {
+	for i := 0; i < n; i++ {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(RequestIDHeader, fmt.Sprintf("request-%d", i))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+}
+
+func TestWithLogging(t *testing.T) {
+	logger, buf := newTestLogger()
+	h := WithLogging(LoggingConfig{Logger: logger})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	serveLogged(h, 1)
+	assert.Contains(t, buf.String(), `"status":201`)
+	assert.Contains(t, buf.String(), `"request_id":"request-0"`)
+	assert.Contains(t, buf.String(), `"level":"INFO"`)
+}
+
+func TestWithLoggingSamplerLogsAllErrors(t *testing.T) {
+	logger, buf := newTestLogger()
+	config := LoggingConfig{
+		Logger:  logger,
+		Sampler: &Sampler{Rate: 0},
+	}
+	h := WithLogging(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	serveLogged(h, 100)
+	assert.Equal(t, 100, strings.Count(buf.String(), "\n"))
+
+	buf.Reset()
+	h = WithLogging(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	serveLogged(h, 100)
+	assert.Equal(t, 100, strings.Count(buf.String(), "\n"))
+}
+
+func TestWithLoggingSamplerRate(t *testing.T) {
+	logger, buf := newTestLogger()
+	h := WithLogging(LoggingConfig{
+		Logger:  logger,
+		Sampler: &Sampler{Rate: 0.25},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serveLogged(h, 2000)
+	logged := strings.Count(buf.String(), "\n")
+	assert.InDelta(t, 500, logged, 100)
+}
+
+func TestSamplerDeterministic(t *testing.T) {
+	s := &Sampler{Rate: 0.5}
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("request-%d", i)
+		assert.Equal(t, s.Sample(id), s.Sample(id))
+	}
+	assert.True(t, (&Sampler{Rate: 1}).Sample("foo"))
+	assert.False(t, (&Sampler{Rate: 0}).Sample("foo"))
+}
+
+func TestKitLogger(t *testing.T) {
+	logger, buf := newTestLogger()
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	r := httptest.NewRequest("GET", "/users", nil)
+	r.Header.Set(RequestIDHeader, "request-1")
+	ctx := WithAuth(r.Context(), testIdentity{id: "42"})
+	kit, _ := newTestKit(r.WithContext(ctx))
+	kit.Logger().Info("user created")
+
+	assert.Contains(t, buf.String(), `"msg":"user created"`)
+	assert.Contains(t, buf.String(), `"request_id":"request-1"`)
+	assert.Contains(t, buf.String(), `"method":"GET"`)
+	assert.Contains(t, buf.String(), `"path":"/users"`)
+	assert.Contains(t, buf.String(), `"user_id":"42"`)
+}
+
+func TestWithLoggingAccessLog(t *testing.T) {
+	logger, buf := newTestLogger()
+	accessLog := &bytes.Buffer{}
+	config := LoggingConfig{
+		Logger:          logger,
+		AccessLog:       accessLog,
+		AccessLogFormat: CombinedLogFormat,
+	}
+	authConfig := AuthenticationConfig{
+		AuthFunc: func(kit *Kit) (Auth, error) {
+			return testIdentity{id: "42"}, nil
+		},
+	}
+	h := WithLogging(config)(OptionalAuth(authConfig)(Handler(func(kit *Kit) error {
+		return kit.Text(http.StatusOK, "hello")
+	})))
+	r := httptest.NewRequest("GET", "/users?page=2", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("Referer", "https://example.com/")
+	r.Header.Set("User-Agent", `curl/8.0 "test"`)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	pattern := `^10\.0\.0\.1 - 42 \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] ` +
+		`"GET /users\?page=2 HTTP/1\.1" 200 5 "https://example\.com/" "curl/8\.0 \\"test\\""\n$`
+	assert.Regexp(t, pattern, accessLog.String())
+	assert.Contains(t, buf.String(), `"msg":"http request"`)
+}
+
+func TestWithLoggingAccessLogOnly(t *testing.T) {
+	logger, buf := newTestLogger()
+	accessLog := &bytes.Buffer{}
+	h := WithLogging(LoggingConfig{
+		Logger:            logger,
+		AccessLog:         accessLog,
+		DisableStructured: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	r := httptest.NewRequest("DELETE", "/users/1", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Regexp(t, `^10\.0\.0\.1 - - \[.+\] "DELETE /users/1 HTTP/1\.1" 404 -\n$`, accessLog.String())
+	assert.Empty(t, buf.String())
+}
+
+func TestWithLoggingSizes(t *testing.T) {
+	logger, buf := newTestLogger()
+	h := WithLogging(LoggingConfig{Logger: logger})(Handler(func(kit *Kit) error {
+		user, err := Bind[createUserRequest](kit)
+		if err != nil {
+			return err
+		}
+		return kit.JSON(http.StatusOK, user)
+	}))
+	body := `{"email": "foo@bar.com", "age": 30}`
+	h.ServeHTTP(httptest.NewRecorder(), newJSONRequest(body))
+
+	record := map[string]any{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, float64(len(body)), record["request_size"])
+	assert.Equal(t, float64(len(`{"email":"foo@bar.com","age":30}`+"\n")), record["response_size"])
+}
+
+func TestWithLoggingCountedRequestSize(t *testing.T) {
+	logger, buf := newTestLogger()
+	h := WithLogging(LoggingConfig{Logger: logger})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	r := httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+	r.ContentLength = -1
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Contains(t, buf.String(), `"request_size":5`)
+	assert.Contains(t, buf.String(), `"response_size":0`)
+}
+
+func TestStatusRecorderFirstWriteHeader(t *testing.T) {
+	rec := NewStatusRecorder(httptest.NewRecorder())
+	rec.WriteHeader(http.StatusEarlyHints)
+	rec.WriteHeader(http.StatusCreated)
+	rec.WriteHeader(http.StatusInternalServerError)
+	assert.Equal(t, http.StatusCreated, rec.Status)
+
+	rec = NewStatusRecorder(httptest.NewRecorder())
+	rec.Write([]byte("ok"))
+	rec.WriteHeader(http.StatusNotFound)
+	assert.Equal(t, http.StatusOK, rec.Status)
+}
+
+func TestWithLoggingDoubleWriteHeader(t *testing.T) {
+	logger, buf := newTestLogger()
+	h := WithLogging(LoggingConfig{Logger: logger})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	serveLogged(h, 1)
+	assert.Contains(t, buf.String(), `"status":201`)
+	assert.Contains(t, buf.String(), `"level":"INFO"`)
+}
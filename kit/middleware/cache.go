@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response stored by WithResponseCache.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// ResponseStore stores the responses cached by WithResponseCache.
+type ResponseStore interface {
+	// Get returns the response cached under the given key, if present
+	// and not expired.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores the response under the given key for ttl.
+	Set(key string, res *CachedResponse, ttl time.Duration)
+	// Delete removes the given key from the store.
+	Delete(key string)
+}
+
+// WithResponseCache caches complete responses of GET and HEAD requests
+// under the key returned by keyFn. A cached response is served without
+// calling the next handler. Requests for which keyFn returns false are
+// not cached. Only 200 OK responses that set no cookies, have no Vary
+// header and are not marked private or no-store are stored. Only the
+// headers set by the next handler are stored, headers of outer
+// middleware, like a request ID, are set per request as usual. The
+// X-Cache header tells whether the response was a HIT or a MISS.
+//
+//	router.Use(middleware.WithResponseCache(store, time.Minute, func(r *http.Request) (string, bool) {
+//		return r.URL.String(), strings.HasPrefix(r.URL.Path, "/blog")
+//	}))
+func WithResponseCache(store ResponseStore, ttl time.Duration, keyFn func(*http.Request) (string, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key, ok := keyFn(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if res, ok := store.Get(key); ok {
+				for name, values := range res.Header {
+					w.Header()[name] = append([]string(nil), values...)
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(res.Status)
+				if r.Method != http.MethodHead {
+					w.Write(res.Body)
+				}
+				return
+			}
+			w.Header().Set("X-Cache", "MISS")
+			rec := &responseRecorder{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+				outer:          w.Header().Clone(),
+			}
+			next.ServeHTTP(rec, r)
+			if r.Method == http.MethodGet && isCacheable(rec) {
+				rec.recordHeader()
+				store.Set(key, &CachedResponse{
+					Status: rec.status,
+					Header: rec.header,
+					Body:   rec.body.Bytes(),
+				}, ttl)
+			}
+		})
+	}
+}
+
+func isCacheable(rec *responseRecorder) bool {
+	if rec.status != http.StatusOK || len(rec.Header().Values("Set-Cookie")) > 0 {
+		return false
+	}
+	// The cache key does not cover the request headers the response
+	// varies on.
+	if len(rec.Header().Values("Vary")) > 0 {
+		return false
+	}
+	cacheControl := strings.ToLower(rec.Header().Get("Cache-Control"))
+	return !strings.Contains(cacheControl, "no-store") && !strings.Contains(cacheControl, "private")
+}
+
+// responseRecorder passes the response through to the client while
+// recording its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+	// outer are the headers set before the next handler ran, header the
+	// ones it set itself when the status was written.
+	outer  http.Header
+	header http.Header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+		rec.recordHeader()
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.wroteHeader = true
+		rec.recordHeader()
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// recordHeader records the headers the next handler added or changed,
+// once.
+func (rec *responseRecorder) recordHeader() {
+	if rec.header != nil {
+		return
+	}
+	rec.header = http.Header{}
+	for name, values := range rec.Header() {
+		if !slices.Equal(rec.outer[name], values) {
+			rec.header[name] = slices.Clone(values)
+		}
+	}
+}
+
+// Unwrap returns the underlying writer so http.ResponseController
+// can access it.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+type responseEntry struct {
+	res       *CachedResponse
+	expiresAt time.Time
+}
+
+// MemoryResponseStore is an in-memory ResponseStore.
+type MemoryResponseStore struct {
+	mu      sync.RWMutex
+	entries map[string]responseEntry
+}
+
+// NewMemoryResponseStore returns a new MemoryResponseStore.
+func NewMemoryResponseStore() *MemoryResponseStore {
+	return &MemoryResponseStore{
+		entries: make(map[string]responseEntry),
+	}
+}
+
+func (s *MemoryResponseStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.res, true
+}
+
+func (s *MemoryResponseStore) Set(key string, res *CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = responseEntry{
+		res:       res,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+func (s *MemoryResponseStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedHandler(calls *int) http.Handler {
+	keyFn := func(r *http.Request) (string, bool) {
+		return r.URL.String(), r.URL.Path != "/private"
+	}
+	return WithResponseCache(NewMemoryResponseStore(), time.Minute, keyFn)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}))
+}
+
+func TestWithResponseCache(t *testing.T) {
+	calls := 0
+	h := newCachedHandler(&calls)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/posts", nil))
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, "hello", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/posts", nil))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "hello", w.Body.String())
+}
+
+func TestWithResponseCacheBypass(t *testing.T) {
+	calls := 0
+	h := newCachedHandler(&calls)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/posts", nil))
+		assert.Empty(t, w.Header().Get("X-Cache"))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/private", nil))
+	}
+	assert.Equal(t, 4, calls)
+}
+
+func TestWithResponseCacheOuterHeaders(t *testing.T) {
+	requests := 0
+	outer := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(100-requests))
+			next.ServeHTTP(w, r)
+		})
+	}
+	calls := 0
+	h := outer(newCachedHandler(&calls))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/posts", nil))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/posts", nil))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "98", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestWithResponseCacheVary(t *testing.T) {
+	calls := 0
+	keyFn := func(r *http.Request) (string, bool) { return r.URL.String(), true }
+	h := WithResponseCache(NewMemoryResponseStore(), time.Minute, keyFn)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Vary", "Accept-Encoding")
+		w.Write([]byte("hello"))
+	}))
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/app.js", nil))
+		assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	}
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxDecompressedSize is the maximum number of bytes of a request body
+// decompressed by WithRequestDecompression.
+var MaxDecompressedSize int64 = 10 << 20
+
+// WithRequestDecompression transparently decompresses request bodies sent
+// with a gzip Content-Encoding, so handlers and the binding helpers see
+// the decoded body. Requests with a malformed gzip body are rejected with
+// 400 Bad Request. The decompressed body is limited to MaxDecompressedSize
+// bytes, which guards against small payloads expanding into huge bodies.
+// Reading past the limit fails with an *http.MaxBytesError, which the
+// binding helpers of kit answer with 413 Request Entity Too Large.
+func WithRequestDecompression() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if r.Body == nil || (encoding != "gzip" && encoding != "x-gzip") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "malformed gzip request body", http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+			r.Body = http.MaxBytesReader(w, &gzipBody{Reader: gz, body: r.Body}, MaxDecompressedSize)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// gzipBody reads the decompressed body while closing the original one.
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipped(t *testing.T, s string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write([]byte(s))
+	assert.Nil(t, err)
+	assert.Nil(t, gz.Close())
+	return buf
+}
+
+func TestWithRequestDecompression(t *testing.T) {
+	var user struct {
+		Email string `json:"email"`
+	}
+	h := WithRequestDecompression()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&user))
+	}))
+	r := httptest.NewRequest("POST", "/", gzipped(t, `{"email": "foo@bar.com"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "foo@bar.com", user.Email)
+}
+
+func TestWithRequestDecompressionPlainBody(t *testing.T) {
+	h := WithRequestDecompression()(okHandler)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("foo")))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWithRequestDecompressionMalformed(t *testing.T) {
+	h := WithRequestDecompression()(okHandler)
+	r := httptest.NewRequest("POST", "/", strings.NewReader("not gzip"))
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWithRequestDecompressionTooLarge(t *testing.T) {
+	defer func(max int64) { MaxDecompressedSize = max }(MaxDecompressedSize)
+	MaxDecompressedSize = 4096
+
+	h := WithRequestDecompression()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		}
+	}))
+	body := gzipped(t, strings.Repeat("a", 1<<20))
+	assert.Less(t, body.Len(), 4096)
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+var maintenancePage templ.Component
+
+// UseMaintenancePage sets the component rendered by WithMaintenance while
+// maintenance mode is enabled. Without a page a plain text message is sent.
+func UseMaintenancePage(c templ.Component) { maintenancePage = c }
+
+// WithMaintenance responds with 503 Service Unavailable and a Retry-After
+// header while enabled returns true. Requests for the allowed paths, and
+// everything below them, are still passed through, which is useful for
+// health checks and admin pages.
+//
+//	router.Use(middleware.WithMaintenance(func() bool {
+//		return os.Getenv("MAINTENANCE") == "true"
+//	}, time.Minute*5, "/health", "/admin"))
+func WithMaintenance(enabled func() bool, retryAfter time.Duration, allow ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled() || isAllowedPath(r.URL.Path, allow) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			}
+			if maintenancePage == nil {
+				http.Error(w, "service is under maintenance", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			maintenancePage.Render(r.Context(), w)
+		})
+	}
+}
+
+func isAllowedPath(path string, allow []string) bool {
+	for _, p := range allow {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideHeader is the header that can override the method of a
+// POST request, see WithMethodOverride.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// WithMethodOverride allows HTML forms, which can only GET and POST, to
+// reach PUT, PATCH and DELETE handlers. The method of POST requests is
+// replaced by the _method form field or the X-HTTP-Method-Override header.
+// Overrides to any other method are ignored.
+//
+//	<form method="POST" action="/users/1">
+//		<input type="hidden" name="_method" value="DELETE"/>
+//	</form>
+func WithMethodOverride() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				method := r.Header.Get(MethodOverrideHeader)
+				if len(method) == 0 {
+					method = r.PostFormValue("_method")
+				}
+				switch method = strings.ToUpper(method); method {
+				case http.MethodPut, http.MethodPatch, http.MethodDelete:
+					r.Method = method
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
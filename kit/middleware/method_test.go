@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMethodOverrideMux() http.Handler {
+	mux := http.NewServeMux()
+	for _, method := range []string{"POST", "PUT", "DELETE"} {
+		mux.HandleFunc(method+" /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(method))
+		})
+	}
+	return WithMethodOverride()(mux)
+}
+
+func TestWithMethodOverride(t *testing.T) {
+	h := newMethodOverrideMux()
+	r := httptest.NewRequest("POST", "/users/1", strings.NewReader("_method=delete&name=foo"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "DELETE", w.Body.String())
+	assert.Equal(t, "foo", r.PostFormValue("name"))
+
+	r = httptest.NewRequest("POST", "/users/1", nil)
+	r.Header.Set(MethodOverrideHeader, "PUT")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "PUT", w.Body.String())
+}
+
+func TestWithMethodOverrideUnsafe(t *testing.T) {
+	h := newMethodOverrideMux()
+	r := httptest.NewRequest("POST", "/users/1", nil)
+	r.Header.Set(MethodOverrideHeader, "GET")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "POST", w.Body.String())
+
+	// Only POST requests can be overridden.
+	r = httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set(MethodOverrideHeader, "DELETE")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
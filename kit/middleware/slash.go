@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+var trailingSlash bool
+
+// UseTrailingSlash sets whether WithTrailingSlash normalizes paths to end
+// with a slash (/path/) instead of without one (/path), the default.
+func UseTrailingSlash(enabled bool) { trailingSlash = enabled }
+
+// WithTrailingSlash normalizes the trailing slash of the request path, see
+// UseTrailingSlash. When redirect is true the client is redirected to the
+// normalized path, with 301 Moved Permanently for GET and HEAD requests
+// and 308 Permanent Redirect for other methods so the method and body are
+// preserved. Otherwise the path is rewritten in place before routing. The
+// root path is left untouched.
+//
+//	router.Use(middleware.WithTrailingSlash(true))
+func WithTrailingSlash(redirect bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := normalizeSlash(r.URL.Path)
+			if path == r.URL.Path {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !redirect {
+				r.URL.Path = path
+				if len(r.URL.RawPath) > 0 {
+					r.URL.RawPath = normalizeSlash(r.URL.RawPath)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			u := *r.URL
+			// Collapse leading slashes, otherwise a path like //evil.com/
+			// redirects to the protocol-relative URL //evil.com.
+			u.Path = collapseLeadingSlashes(path)
+			if len(u.RawPath) > 0 {
+				u.RawPath = collapseLeadingSlashes(normalizeSlash(u.RawPath))
+			}
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			http.Redirect(w, r, u.RequestURI(), status)
+		})
+	}
+}
+
+func normalizeSlash(path string) string {
+	if path == "/" || len(path) == 0 {
+		return path
+	}
+	path = strings.TrimRight(path, "/")
+	if len(path) == 0 {
+		return "/"
+	}
+	if trailingSlash {
+		return path + "/"
+	}
+	return path
+}
+
+// collapseLeadingSlashes makes sure the path starts with exactly one slash.
+// Backslashes are collapsed too, since browsers treat them as slashes.
+func collapseLeadingSlashes(path string) string {
+	return "/" + strings.TrimLeft(path, `/\`)
+}
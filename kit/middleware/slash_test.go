@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTrailingSlashRedirect(t *testing.T) {
+	h := WithTrailingSlash(true)(okHandler)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/users/?page=2", nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/users?page=2", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/users/", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "/users", w.Header().Get("Location"))
+
+	for _, path := range []string{"/", "/users"} {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func TestWithTrailingSlashRewrite(t *testing.T) {
+	var path string
+	h := WithTrailingSlash(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/users/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/users", path)
+}
+
+func TestUseTrailingSlash(t *testing.T) {
+	UseTrailingSlash(true)
+	defer UseTrailingSlash(false)
+
+	h := WithTrailingSlash(true)(okHandler)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/users/", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWithTrailingSlashOpenRedirect(t *testing.T) {
+	h := WithTrailingSlash(true)(okHandler)
+	for _, line := range []string{"GET //evil.com/ HTTP/1.1", `GET /\evil.com/ HTTP/1.1`} {
+		r, err := http.ReadRequest(bufio.NewReader(strings.NewReader(line + "\r\nHost: example.com\r\n\r\n")))
+		assert.Nil(t, err)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "/evil.com", w.Header().Get("Location"))
+	}
+}
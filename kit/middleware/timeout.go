@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithHeaderTimeout applies the timeout supplied by the client in the
+// given header, e.g. X-Request-Timeout: 2s, to the request context. The
+// value is either a Go duration or a number of seconds, and is clamped to
+// max. Requests without a valid timeout use max.
+//
+//	router.Use(middleware.WithHeaderTimeout("X-Request-Timeout", 10*time.Second))
+func WithHeaderTimeout(header string, max time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := parseTimeout(r.Header.Get(header))
+			if timeout <= 0 || timeout > max {
+				timeout = max
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func parseTimeout(value string) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return d
+}
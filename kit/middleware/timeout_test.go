@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveHeaderTimeout(value string) time.Duration {
+	var remaining time.Duration
+	h := WithHeaderTimeout("X-Request-Timeout", 10*time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ := r.Context().Deadline()
+		remaining = time.Until(deadline)
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	if len(value) > 0 {
+		r.Header.Set("X-Request-Timeout", value)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	return remaining
+}
+
+func TestWithHeaderTimeout(t *testing.T) {
+	assert.InDelta(t, 2*time.Second, serveHeaderTimeout("2s"), float64(100*time.Millisecond))
+	assert.InDelta(t, 500*time.Millisecond, serveHeaderTimeout("0.5"), float64(100*time.Millisecond))
+}
+
+func TestWithHeaderTimeoutClamped(t *testing.T) {
+	assert.InDelta(t, 10*time.Second, serveHeaderTimeout("1m"), float64(100*time.Millisecond))
+}
+
+func TestWithHeaderTimeoutDefault(t *testing.T) {
+	assert.InDelta(t, 10*time.Second, serveHeaderTimeout(""), float64(100*time.Millisecond))
+	assert.InDelta(t, 10*time.Second, serveHeaderTimeout("foo"), float64(100*time.Millisecond))
+	assert.InDelta(t, 10*time.Second, serveHeaderTimeout("-1s"), float64(100*time.Millisecond))
+}
@@ -0,0 +1,78 @@
+package kit
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// CorrelationHeaders are the headers OutboundRequest copies from the
+// current request onto outbound requests, which are the request ID and
+// the W3C trace context by default.
+var CorrelationHeaders = []string{RequestIDHeader, "traceparent", "tracestate"}
+
+// OutboundRequest returns a copy of the given outbound request carrying
+// the correlation headers of the current request, so calls to downstream
+// services can be traced back to it. Headers already set on the outbound
+// request are kept.
+//
+//	req, _ := http.NewRequest("GET", "http://billing/invoices", nil)
+//	resp, err := http.DefaultClient.Do(kit.OutboundRequest(req))
+func (kit *Kit) OutboundRequest(req *http.Request) *http.Request {
+	out := req.Clone(req.Context())
+	for _, name := range CorrelationHeaders {
+		if len(out.Header.Values(name)) > 0 {
+			continue
+		}
+		for _, value := range kit.Request.Header.Values(name) {
+			out.Header.Add(name, value)
+		}
+	}
+	return out
+}
+
+// Do sends the outbound request with the given client, or
+// http.DefaultClient if nil, carrying the correlation headers of the
+// current request. The request keeps its own context and is also bound to
+// the context of the current request, hence it is cancelled as soon as
+// its own deadline passes or the client of the current request
+// disconnects. Closing the response body releases the binding.
+//
+//	ctx, cancel := context.WithTimeout(kit.Request.Context(), 2*time.Second)
+//	defer cancel()
+//	req, _ := http.NewRequestWithContext(ctx, "GET", "http://billing/invoices", nil)
+//	resp, err := kit.Do(billingClient, req)
+func (kit *Kit) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	inbound := kit.Request.Context()
+	ctx, cancel := context.WithCancelCause(req.Context())
+	stop := context.AfterFunc(inbound, func() {
+		cancel(context.Cause(inbound))
+	})
+	release := func() {
+		stop()
+		cancel(nil)
+	}
+	resp, err := client.Do(kit.OutboundRequest(req.WithContext(ctx)))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseBody releases the context of an outbound request once its
+// response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
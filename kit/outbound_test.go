@@ -0,0 +1,103 @@
+package kit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboundRequest(t *testing.T) {
+	var out *http.Request
+	h := WithRequestID(Handler(func(kit *Kit) error {
+		req, err := http.NewRequest("GET", "http://billing/invoices", nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("tracestate", "billing=1")
+		out = kit.OutboundRequest(req)
+		return nil
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set("tracestate", "app=1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, w.Header().Get(RequestIDHeader), out.Header.Get(RequestIDHeader))
+	assert.NotEmpty(t, out.Header.Get(RequestIDHeader))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", out.Header.Get("traceparent"))
+	assert.Equal(t, "billing=1", out.Header.Get("tracestate"))
+}
+
+func TestDo(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	r.Header.Set(RequestIDHeader, "request-1")
+	kit, _ := newTestKit(r)
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	assert.Nil(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := kit.Do(server.Client(), req)
+		done <- err
+	}()
+	assert.Equal(t, "request-1", (<-received).Header.Get(RequestIDHeader))
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestDoCallerDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	assert.Nil(t, err)
+
+	start := time.Now()
+	_, err = kit.Do(server.Client(), req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestDoReadsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("invoices"))
+	}))
+	defer server.Close()
+
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	req, err := http.NewRequest("GET", server.URL, nil)
+	assert.Nil(t, err)
+	resp, err := kit.Do(server.Client(), req)
+	assert.Nil(t, err)
+	b, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "invoices", string(b))
+	assert.Nil(t, resp.Body.Close())
+}
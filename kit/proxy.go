@@ -0,0 +1,100 @@
+package kit
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+var trustedProxies []netip.Prefix
+
+// SetTrustedProxies sets the proxies whose forwarded headers
+// (X-Forwarded-For, X-Forwarded-Proto, X-Forwarded-Host) are trusted.
+// Both CIDRs and single IP addresses are accepted. Forwarded headers of
+// requests coming from any other address are ignored, which prevents
+// clients from spoofing them.
+//
+//	kit.SetTrustedProxies("10.0.0.0/8", "127.0.0.1")
+func SetTrustedProxies(cidrs ...string) error {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return fmt.Errorf("invalid trusted proxy (%s): %w", cidr, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy (%s): %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+// Scheme returns the scheme (http or https) the client used. Behind a
+// trusted proxy the X-Forwarded-Proto header is respected.
+func (kit *Kit) Scheme() string {
+	if isTrustedProxy(kit.Request.RemoteAddr) {
+		if proto := firstHeaderValue(kit.Request.Header.Get("X-Forwarded-Proto")); len(proto) > 0 {
+			return strings.ToLower(proto)
+		}
+	}
+	if kit.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// ClientIP returns the IP address of the client. Behind a trusted proxy
+// the X-Forwarded-For header is respected.
+func (kit *Kit) ClientIP() string {
+	remoteIP := hostOnly(kit.Request.RemoteAddr)
+	if !isTrustedProxy(kit.Request.RemoteAddr) {
+		return remoteIP
+	}
+	forwarded := strings.Split(kit.Request.Header.Get("X-Forwarded-For"), ",")
+	// Walk from right to left, the right most untrusted address is the client.
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if len(ip) == 0 {
+			continue
+		}
+		if !isTrustedProxy(ip) {
+			return ip
+		}
+	}
+	return remoteIP
+}
+
+func isTrustedProxy(addr string) bool {
+	ip, err := netip.ParseAddr(hostOnly(addr))
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOnly(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func firstHeaderValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
@@ -0,0 +1,47 @@
+package kit
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemeWithoutTrustedProxy(t *testing.T) {
+	assert.Nil(t, SetTrustedProxies())
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "http", kit.Scheme())
+	assert.Equal(t, "10.0.0.1", kit.ClientIP())
+
+	r.TLS = &tls.ConnectionState{}
+	assert.Equal(t, "https", kit.Scheme())
+}
+
+func TestSchemeWithTrustedProxy(t *testing.T) {
+	assert.Nil(t, SetTrustedProxies("10.0.0.0/8", "192.168.1.1"))
+	defer SetTrustedProxies()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-Proto", "HTTPS")
+	r.Header.Set("X-Forwarded-For", "6.6.6.6, 1.2.3.4, 192.168.1.1")
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "https", kit.Scheme())
+	assert.Equal(t, "1.2.3.4", kit.ClientIP())
+
+	// Spoofed headers from an untrusted address are ignored.
+	r.RemoteAddr = "6.6.6.6:1234"
+	assert.Equal(t, "http", kit.Scheme())
+	assert.Equal(t, "6.6.6.6", kit.ClientIP())
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	assert.NotNil(t, SetTrustedProxies("foo"))
+	assert.NotNil(t, SetTrustedProxies("10.0.0.0/99"))
+}
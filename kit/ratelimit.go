@@ -0,0 +1,89 @@
+package kit
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits every client, identified by Kit.ClientIP, to the
+// given number of requests per window. The X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (in seconds) headers are
+// set on every response, so clients can throttle themselves. Clients
+// exceeding the limit are passed to the error handler with
+// ErrTooManyRequests.
+func WithRateLimit(limit int, window time.Duration) func(http.Handler) http.Handler {
+	limiter := newRateLimiter(limit, window)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			kit := &Kit{
+				Response: w,
+				Request:  r,
+			}
+			remaining, reset, ok := limiter.allow(kit.ClientIP(), time.Now())
+			resetSeconds := strconv.Itoa(int(reset.Round(time.Second).Seconds()))
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", resetSeconds)
+			if !ok {
+				w.Header().Set("Retry-After", resetSeconds)
+				errorHandler(kit, ErrTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type rateLimitWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// rateLimiter is a fixed window rate limiter.
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*rateLimitWindow
+	lastSweep time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateLimitWindow),
+	}
+}
+
+// allow counts a request of the given client, returning the remaining
+// requests, the time until the window resets and whether the request
+// is allowed.
+func (l *rateLimiter) allow(key string, now time.Time) (int, time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > l.window {
+		l.sweep(now)
+	}
+	client, ok := l.clients[key]
+	if !ok || !now.Before(client.resetAt) {
+		client = &rateLimitWindow{resetAt: now.Add(l.window)}
+		l.clients[key] = client
+	}
+	client.count++
+	remaining := max(l.limit-client.count, 0)
+	return remaining, client.resetAt.Sub(now), client.count <= l.limit
+}
+
+// sweep removes the clients whose window expired.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, client := range l.clients {
+		if !now.Before(client.resetAt) {
+			delete(l.clients, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,58 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	h := WithRateLimit(2, time.Minute)(okHandler)
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("1.1.1.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("X-RateLimit-Reset"))
+
+	w = serve("1.1.1.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = serve("1.1.1.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Other clients have their own limit.
+	w = serve("2.2.2.2:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestRateLimiterReset(t *testing.T) {
+	limiter := newRateLimiter(1, time.Minute)
+	now := time.Now()
+	remaining, reset, ok := limiter.allow("foo", now)
+	assert.True(t, ok)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, time.Minute, reset)
+
+	_, reset, ok = limiter.allow("foo", now.Add(30*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, reset)
+
+	remaining, _, ok = limiter.allow("foo", now.Add(time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 0, remaining)
+}
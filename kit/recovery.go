@@ -0,0 +1,64 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// Keys of the Kit locals holding the recovered value and the stack
+// of a panic, available to the error handler.
+const (
+	RecoveredValueKey = "superkit.recovered"
+	RecoveredStackKey = "superkit.stack"
+)
+
+// PanicHandlerFunc handles a panic recovered by WithRecovery.
+type PanicHandlerFunc func(kit *Kit, recovered any, stack []byte)
+
+var panicHandler PanicHandlerFunc = DefaultPanicHandler
+
+// UsePanicHandler sets the handler of panics recovered by WithRecovery,
+// which is distinct from the error handler so panics can be reported
+// separately, e.g. to an error tracker.
+func UsePanicHandler(h PanicHandlerFunc) { panicHandler = h }
+
+// DefaultPanicHandler passes the recovered value to the error handler.
+// Panicking with an APIError responds with its status, any other value
+// is logged with its stack and results in a 500.
+func DefaultPanicHandler(kit *Kit, recovered any, stack []byte) {
+	var apiErr *APIError
+	err, ok := recovered.(error)
+	if !ok || !errors.As(err, &apiErr) {
+		defaultLogger().Error("recovered from panic", "recovered", recovered, "stack", string(stack))
+		err = ErrInternalServer.Wrap(fmt.Errorf("panic: %v", recovered))
+	}
+	errorHandler(kit, err)
+}
+
+// WithRecovery recovers from panics in the next handler and passes them
+// to the panic handler, see UsePanicHandler. Panics with
+// http.ErrAbortHandler are re-panicked to preserve their semantics.
+func WithRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Share the Kit with the handler, so its locals are available to
+		// the error handler.
+		r = kitFor(w, r).Request
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			stack := debug.Stack()
+			kit := kitFor(w, r)
+			kit.Set(RecoveredValueKey, recovered)
+			kit.Set(RecoveredStackKey, stack)
+			panicHandler(kit, recovered, stack)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,99 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func panicking(v any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(v)
+	})
+}
+
+func TestWithRecoveryAPIError(t *testing.T) {
+	w := httptest.NewRecorder()
+	WithRecovery(panicking(ErrForbidden)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestWithRecoveryString(t *testing.T) {
+	defer UseErrorHandler(DefaultErrorHandler)
+	var (
+		recovered any
+		stack     []byte
+	)
+	UseErrorHandler(func(kit *Kit, err error) {
+		recovered = kit.Get(RecoveredValueKey)
+		stack, _ = kit.Get(RecoveredStackKey).([]byte)
+		DefaultErrorHandler(kit, err)
+	})
+
+	w := httptest.NewRecorder()
+	WithRecovery(panicking("boom")).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "boom", recovered)
+	assert.Contains(t, string(stack), "panicking")
+}
+
+func TestWithRecoveryAbortHandler(t *testing.T) {
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		w := httptest.NewRecorder()
+		WithRecovery(panicking(http.ErrAbortHandler)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	})
+}
+
+func TestWithRecoverySharesKit(t *testing.T) {
+	var userID any
+	UseErrorHandler(func(kit *Kit, err error) {
+		userID = kit.Get("user_id")
+		DefaultErrorHandler(kit, err)
+	})
+	defer UseErrorHandler(DefaultErrorHandler)
+
+	h := WithRecovery(Handler(func(kit *Kit) error {
+		kit.Set("user_id", 42)
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 42, userID)
+}
+
+func TestUsePanicHandler(t *testing.T) {
+	var (
+		recovered any
+		stack     []byte
+		errored   bool
+	)
+	UsePanicHandler(func(kit *Kit, v any, s []byte) {
+		recovered, stack = v, s
+		kit.Text(http.StatusInternalServerError, "panicked")
+	})
+	defer UsePanicHandler(DefaultPanicHandler)
+	UseErrorHandler(func(kit *Kit, err error) { errored = true })
+	defer UseErrorHandler(DefaultErrorHandler)
+
+	w := httptest.NewRecorder()
+	WithRecovery(panicking("boom")).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "panicked", w.Body.String())
+	assert.Equal(t, "boom", recovered)
+	assert.Contains(t, string(stack), "panicking")
+	assert.False(t, errored)
+}
+
+func TestWithRecoveryHead(t *testing.T) {
+	h := WithRecovery(Handler(func(kit *Kit) error {
+		panic("boom")
+	}))
+	for _, method := range []string{"GET", "HEAD"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code, method)
+	}
+}
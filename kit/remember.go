@@ -0,0 +1,172 @@
+package kit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RememberCookieName is the name of the cookie holding the remember me
+// token.
+const RememberCookieName = "superkit-remember"
+
+// RememberDuration is how long a remember me token stays valid.
+var RememberDuration = 30 * 24 * time.Hour
+
+var (
+	// ErrRememberTokenInvalid is returned when a remember me token is
+	// missing, unknown, expired or was already used.
+	ErrRememberTokenInvalid = errors.New("invalid remember token")
+	// ErrNoRememberStore is returned when no RememberStore was set with
+	// UseRememberStore.
+	ErrNoRememberStore = errors.New("no remember store configured")
+)
+
+// RememberStore persists the remember me tokens. Only the SHA-256 hash of
+// a token is stored, so a leaked store can not be used to log in.
+type RememberStore interface {
+	// Save stores the token hash for the given user until it expires.
+	Save(hash, userID string, expires time.Time) error
+	// Consume removes the token hash and returns the user it belongs to.
+	// Unknown or expired hashes result in ErrRememberTokenInvalid.
+	Consume(hash string) (string, error)
+}
+
+var rememberStore RememberStore
+
+// UseRememberStore sets the RememberStore backing Remember and
+// RememberedUser.
+func UseRememberStore(s RememberStore) { rememberStore = s }
+
+// Remember issues a new remember me token for the given user and sets it
+// as a secure, HTTP only cookie, so the user can skip the login when
+// returning. Call it after a successful login.
+func (kit *Kit) Remember(userID string) error {
+	if rememberStore == nil {
+		return ErrNoRememberStore
+	}
+	token, err := GenerateToken(32)
+	if err != nil {
+		return err
+	}
+	expires := time.Now().Add(RememberDuration)
+	if err := rememberStore.Save(hashRememberToken(token), userID, expires); err != nil {
+		return err
+	}
+	http.SetCookie(kit.Response, &http.Cookie{
+		Name:     RememberCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   !IsDevelopment(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// RememberedUser validates the remember me cookie of the request and
+// returns the ID of the user it belongs to. A valid token is rotated, the
+// old token can not be used again, which limits the damage of a stolen
+// cookie.
+func (kit *Kit) RememberedUser() (string, error) {
+	if rememberStore == nil {
+		return "", ErrNoRememberStore
+	}
+	cookie, err := kit.Request.Cookie(RememberCookieName)
+	if err != nil {
+		return "", ErrRememberTokenInvalid
+	}
+	userID, err := rememberStore.Consume(hashRememberToken(cookie.Value))
+	if err != nil {
+		return "", err
+	}
+	return userID, kit.Remember(userID)
+}
+
+// Forget invalidates the remember me token of the request and removes the
+// cookie. Call it when the user logs out.
+func (kit *Kit) Forget() error {
+	if rememberStore == nil {
+		return ErrNoRememberStore
+	}
+	if cookie, err := kit.Request.Cookie(RememberCookieName); err == nil {
+		rememberStore.Consume(hashRememberToken(cookie.Value))
+	}
+	http.SetCookie(kit.Response, &http.Cookie{
+		Name:     RememberCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   !IsDevelopment(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// rememberAuth returns the Auth of the user of a valid remember me token
+// if the request is not authenticated otherwise.
+func rememberAuth(kit *Kit, config AuthenticationConfig, auth Auth) (Auth, error) {
+	if auth.Check() || config.RememberFunc == nil {
+		return auth, nil
+	}
+	userID, err := kit.RememberedUser()
+	if err != nil {
+		return auth, nil
+	}
+	remembered, err := config.RememberFunc(kit, userID)
+	if err != nil || remembered == nil {
+		return auth, err
+	}
+	return remembered, nil
+}
+
+func hashRememberToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type rememberEntry struct {
+	userID    string
+	expiresAt time.Time
+}
+
+// MemoryRememberStore is an in-memory RememberStore. Tokens are lost on
+// restart, hence it is mostly useful for development and tests.
+type MemoryRememberStore struct {
+	mu      sync.Mutex
+	entries map[string]rememberEntry
+}
+
+// NewMemoryRememberStore returns a new MemoryRememberStore.
+func NewMemoryRememberStore() *MemoryRememberStore {
+	return &MemoryRememberStore{
+		entries: make(map[string]rememberEntry),
+	}
+}
+
+func (s *MemoryRememberStore) Save(hash, userID string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[hash] = rememberEntry{userID: userID, expiresAt: expires}
+	return nil
+}
+
+func (s *MemoryRememberStore) Consume(hash string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[hash]
+	if !ok {
+		return "", ErrRememberTokenInvalid
+	}
+	delete(s.entries, hash)
+	if time.Now().After(entry.expiresAt) {
+		return "", ErrRememberTokenInvalid
+	}
+	return entry.userID, nil
+}
@@ -0,0 +1,108 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rememberCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == RememberCookieName {
+			return cookie
+		}
+	}
+	t.Fatal("remember cookie not set")
+	return nil
+}
+
+func newRememberRequest(cookie *http.Cookie) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	return r
+}
+
+func TestRemember(t *testing.T) {
+	UseRememberStore(NewMemoryRememberStore())
+	defer UseRememberStore(nil)
+
+	kit, w := newTestKit(httptest.NewRequest("POST", "/login", nil))
+	assert.Nil(t, kit.Remember("42"))
+	cookie := rememberCookie(t, w)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.NotEmpty(t, cookie.Value)
+
+	// Validating the token rotates it.
+	kit, w = newTestKit(newRememberRequest(cookie))
+	userID, err := kit.RememberedUser()
+	assert.Nil(t, err)
+	assert.Equal(t, "42", userID)
+	rotated := rememberCookie(t, w)
+	assert.NotEqual(t, cookie.Value, rotated.Value)
+
+	// The old token can not be reused.
+	kit, _ = newTestKit(newRememberRequest(cookie))
+	_, err = kit.RememberedUser()
+	assert.ErrorIs(t, err, ErrRememberTokenInvalid)
+
+	kit, _ = newTestKit(newRememberRequest(rotated))
+	userID, err = kit.RememberedUser()
+	assert.Nil(t, err)
+	assert.Equal(t, "42", userID)
+}
+
+func TestForget(t *testing.T) {
+	UseRememberStore(NewMemoryRememberStore())
+	defer UseRememberStore(nil)
+
+	kit, w := newTestKit(httptest.NewRequest("POST", "/login", nil))
+	assert.Nil(t, kit.Remember("42"))
+	cookie := rememberCookie(t, w)
+
+	kit, w = newTestKit(newRememberRequest(cookie))
+	assert.Nil(t, kit.Forget())
+	assert.Equal(t, -1, rememberCookie(t, w).MaxAge)
+
+	kit, _ = newTestKit(newRememberRequest(cookie))
+	_, err := kit.RememberedUser()
+	assert.ErrorIs(t, err, ErrRememberTokenInvalid)
+}
+
+func TestRememberWithoutStore(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("POST", "/login", nil))
+	assert.ErrorIs(t, kit.Remember("42"), ErrNoRememberStore)
+}
+
+func TestWithAuthenticationRemember(t *testing.T) {
+	UseRememberStore(NewMemoryRememberStore())
+	defer UseRememberStore(nil)
+
+	kit, w := newTestKit(httptest.NewRequest("POST", "/login", nil))
+	assert.Nil(t, kit.Remember("42"))
+	cookie := rememberCookie(t, w)
+
+	config := anonymousAuthConfig()
+	config.RememberFunc = func(kit *Kit, userID string) (Auth, error) {
+		id, err := strconv.Atoi(userID)
+		return testAuth{ID: id}, err
+	}
+	var auth Auth
+	h := RequireAuth(config)(Handler(func(kit *Kit) error {
+		auth = kit.Auth()
+		return nil
+	}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, newRememberRequest(cookie))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, testAuth{ID: 42}, auth)
+	assert.NotEqual(t, cookie.Value, rememberCookie(t, w).Value)
+
+	// The old token is rejected and the user has to log in again.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, newRememberRequest(cookie))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+}
@@ -0,0 +1,167 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// FlashSessionName is the name of the session holding the flash messages.
+const FlashSessionName = "superkit-flash"
+
+// AddFlash adds a flash message that will be available while rendering
+// the next request.
+func (kit *Kit) AddFlash(msg string) error {
+	sess := kit.GetSession(FlashSessionName)
+	sess.AddFlash(msg)
+	return sess.Save(kit.Request, kit.Response)
+}
+
+// RenderContext returns the request context augmented with the values
+// shared by all components, like the current Auth, the flash messages and
+// the locale. Render uses it automatically, hence components can access
+// these values with the view helpers.
+//
+//	view.Flashes(ctx)
+func (kit *Kit) RenderContext() context.Context {
+	if kit.renderCtx != nil {
+		return kit.renderCtx
+	}
+	ctx := kit.Request.Context()
+	if _, ok := AuthFromContext(ctx); !ok {
+		ctx = WithAuth(ctx, DefaultAuth{})
+	}
+	ctx = WithContextValue(ctx, flashKey, kit.flashes())
+	ctx = WithContextValue(ctx, localeKey, kit.Locale())
+	if len(kit.cspNonce) > 0 {
+		ctx = templ.WithNonce(ctx, kit.cspNonce)
+	}
+	kit.renderCtx = ctx
+	return ctx
+}
+
+// flashes consumes the flash messages of the current request.
+func (kit *Kit) flashes() []string {
+	sess := kit.GetSession(FlashSessionName)
+	values := sess.Flashes()
+	if len(values) == 0 {
+		return nil
+	}
+	// Save the session so the consumed flashes will not be shown again.
+	sess.Save(kit.Request, kit.Response)
+	flashes := make([]string, 0, len(values))
+	for _, value := range values {
+		if msg, ok := value.(string); ok {
+			flashes = append(flashes, msg)
+		}
+	}
+	return flashes
+}
+
+// OOB renders the main component followed by additional out-of-band
+// fragments in a single HTML response, allowing HTMX to update multiple
+// parts of the page. The fragments should be marked with hx-swap-oob.
+//
+//	return kit.OOB(userList(users), userCount(len(users)))
+func (kit *Kit) OOB(components ...templ.Component) error {
+	return kit.renderOOB(0, components)
+}
+
+// RenderStatusOOB is like OOB, but responds with the given status, e.g. a
+// 422 Unprocessable Entity while swapping in the validation errors. Note
+// that HTMX does not swap non 2xx responses by default, pair it with the
+// HX-Reswap header or the response-targets extension.
+//
+//	return kit.RenderStatusOOB(http.StatusUnprocessableEntity, form(values, errs), toast("invalid input"))
+func (kit *Kit) RenderStatusOOB(status int, main templ.Component, oob ...templ.Component) error {
+	return kit.renderOOB(status, append([]templ.Component{main}, oob...))
+}
+
+// FailRender renders the component mapped to the status of the given
+// *APIError with that status, e.g. a validation fragment for a 422. Errors
+// without a mapped component are returned as is, hence handled by the
+// error handler.
+//
+//	return kit.FailRender(err, map[int]templ.Component{
+//		http.StatusUnprocessableEntity: form(values, errs),
+//		http.StatusConflict:            emailTaken(values.Email),
+//	})
+func (kit *Kit) FailRender(err error, byStatus map[int]templ.Component) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	c, ok := byStatus[apiErr.Status]
+	if !ok {
+		return err
+	}
+	return kit.renderOOB(apiErr.Status, []templ.Component{c})
+}
+
+// renderOOB renders the components as a single HTML response. A zero
+// status leaves writing the status to the first write.
+func (kit *Kit) renderOOB(status int, components []templ.Component) error {
+	if kit.aborted {
+		return nil
+	}
+	if contentType := kit.Response.Header().Get("Content-Type"); len(contentType) > 0 {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || mediaType != "text/html" {
+			return fmt.Errorf("out-of-band swaps require a text/html response got %s", contentType)
+		}
+	} else {
+		kit.Response.Header().Set("Content-Type", withCharset("text/html"))
+	}
+	// The render context saves the flash session, which must happen
+	// before the status is written.
+	ctx := kit.RenderContext()
+	if status > 0 {
+		kit.Response.WriteHeader(status)
+	}
+	w := newRenderWriter(ctx, kit.Response)
+	for _, c := range components {
+		if err := c.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// PrefersReducedData reports whether the client asked for a reduced data
+// usage with the Save-Data: on header, e.g. a browser in data saver mode.
+func (kit *Kit) PrefersReducedData() bool {
+	return strings.EqualFold(strings.TrimSpace(kit.Request.Header.Get("Save-Data")), "on")
+}
+
+// RenderLite renders the lighter variant of a page served to clients
+// preferring reduced data, see RenderFull.
+//
+//	if kit.PrefersReducedData() {
+//		return kit.RenderLite(home.IndexLite())
+//	}
+//	return kit.RenderFull(home.Index())
+func (kit *Kit) RenderLite(c templ.Component) error {
+	kit.varySaveData()
+	return kit.Render(c)
+}
+
+// RenderFull renders the complete variant of a page of which a lighter
+// variant is served to clients preferring reduced data, see RenderLite.
+func (kit *Kit) RenderFull(c templ.Component) error {
+	kit.varySaveData()
+	return kit.Render(c)
+}
+
+// varySaveData tells caches the response depends on the Save-Data header.
+func (kit *Kit) varySaveData() {
+	for _, v := range kit.Response.Header().Values("Vary") {
+		if strings.Contains(strings.ToLower(v), "save-data") {
+			return
+		}
+	}
+	kit.Response.Header().Add("Vary", "Save-Data")
+}
@@ -0,0 +1,215 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuth struct {
+	ID int
+}
+
+func (auth testAuth) Check() bool { return auth.ID > 0 }
+
+func TestRenderContext(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.AddFlash("welcome back"))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	r = r.WithContext(WithAuth(r.Context(), testAuth{ID: 1}))
+	kit, w = newTestKit(r)
+
+	component := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		auth := ctx.Value(authKey).(Auth)
+		flashes := FlashesFromContext(ctx)
+		_, err := fmt.Fprintf(w, "%v %s", auth.Check(), strings.Join(flashes, ","))
+		return err
+	})
+	assert.Nil(t, kit.Render(component))
+	assert.Equal(t, "true welcome back", w.Body.String())
+}
+
+func TestRenderContextDefaults(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	ctx := kit.RenderContext()
+	assert.Equal(t, DefaultAuth{}, ctx.Value(authKey))
+	assert.Empty(t, FlashesFromContext(ctx))
+}
+
+func TestRenderFlushesLargeOutput(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	chunk := strings.Repeat("a", renderBufferSize)
+	written := 0
+	component := templ.ComponentFunc(func(_ context.Context, cw io.Writer) error {
+		io.WriteString(cw, chunk)
+		// The first chunk has been flushed while the component is
+		// still rendering.
+		written = w.Body.Len()
+		_, err := io.WriteString(cw, "b")
+		return err
+	})
+	assert.Nil(t, kit.Render(component))
+	assert.Equal(t, renderBufferSize, written)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, chunk+"b", w.Body.String())
+}
+
+func TestRenderContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	component := templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "<h1>"); err != nil {
+			return err
+		}
+		cancel()
+		_, err := io.WriteString(w, "slow content</h1>")
+		return err
+	})
+	assert.ErrorIs(t, kit.Render(component), context.Canceled)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestOOB(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	err := kit.OOB(
+		textComponent(`<ul id="users"><li>foo</li></ul>`),
+		textComponent(`<span id="count" hx-swap-oob="true">1</span>`),
+		textComponent(`<div id="flash" hx-swap-oob="true">created</div>`),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `<ul id="users"><li>foo</li></ul>`+
+		`<span id="count" hx-swap-oob="true">1</span>`+
+		`<div id="flash" hx-swap-oob="true">created</div>`, w.Body.String())
+}
+
+func TestOOBNonHTMLResponse(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	kit.Header("Content-Type", "application/json")
+	assert.NotNil(t, kit.OOB(textComponent("foo")))
+	assert.Empty(t, w.Body.String())
+}
+
+func renderSaveData(r *http.Request) *httptest.ResponseRecorder {
+	kit, w := newTestKit(r)
+	if kit.PrefersReducedData() {
+		kit.RenderLite(textComponent("lite"))
+	} else {
+		kit.RenderFull(textComponent("full"))
+	}
+	return w
+}
+
+func TestPrefersReducedData(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Save-Data", "on")
+	w := renderSaveData(r)
+	assert.Equal(t, "lite", w.Body.String())
+	assert.Equal(t, "Save-Data", w.Header().Get("Vary"))
+
+	w = renderSaveData(httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "full", w.Body.String())
+	assert.Equal(t, "Save-Data", w.Header().Get("Vary"))
+}
+
+func TestRenderStatusOOB(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("POST", "/users", nil))
+	err := kit.RenderStatusOOB(http.StatusUnprocessableEntity,
+		textComponent(`<form id="user">invalid email</form>`),
+		textComponent(`<div id="toast" hx-swap-oob="true">error</div>`),
+		textComponent(`<span id="count" hx-swap-oob="true">1 error</span>`),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `<form id="user">invalid email</form>`+
+		`<div id="toast" hx-swap-oob="true">error</div>`+
+		`<span id="count" hx-swap-oob="true">1 error</span>`, w.Body.String())
+}
+
+func TestFailRender(t *testing.T) {
+	byStatus := map[int]templ.Component{
+		http.StatusUnprocessableEntity: textComponent(`<form id="user">invalid email</form>`),
+	}
+	h := Handler(func(kit *Kit) error {
+		if kit.Request.URL.Query().Has("invalid") {
+			return kit.FailRender(ErrUnprocessableEntity.Wrap(fmt.Errorf("invalid email")), byStatus)
+		}
+		return kit.FailRender(ErrInternalServer, byStatus)
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/users?invalid", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `<form id="user">invalid email</form>`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestRenderDefaults(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.Render(textComponent("<p>hi</p>")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	defer func(contentType string, status int) {
+		RenderContentType, RenderDefaultStatus = contentType, status
+	}(RenderContentType, RenderDefaultStatus)
+	RenderContentType = "image/svg+xml"
+	RenderDefaultStatus = http.StatusAccepted
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/logo.svg", nil))
+	assert.Nil(t, kit.Render(textComponent("<svg></svg>")))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, "<svg></svg>", w.Body.String())
+
+	kit, w = newTestKit(newFlashRequest(t, "saved"))
+	assert.Nil(t, kit.Render(textComponent("<svg></svg>")))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NotEmpty(t, w.Result().Header.Get("Set-Cookie"))
+
+	kit, w = newTestKit(httptest.NewRequest("GET", "/feed.xml", nil))
+	kit.Header("Content-Type", "application/atom+xml")
+	assert.Nil(t, kit.Render(textComponent("<feed></feed>")))
+	assert.Equal(t, "application/atom+xml", w.Header().Get("Content-Type"))
+}
+
+// newFlashRequest returns a request carrying a flash message.
+func newFlashRequest(t *testing.T, msg string) *http.Request {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, kit.AddFlash(msg))
+	r := httptest.NewRequest("POST", "/users", nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func TestRenderStatusOOBConsumesFlashes(t *testing.T) {
+	kit, w := newTestKit(newFlashRequest(t, "saved"))
+	flash := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, strings.Join(FlashesFromContext(ctx), ","))
+		return err
+	})
+	assert.Nil(t, kit.RenderStatusOOB(http.StatusUnprocessableEntity, flash))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "saved", w.Body.String())
+	// The session without the consumed flash is saved.
+	assert.NotEmpty(t, w.Result().Header.Get("Set-Cookie"))
+}
@@ -0,0 +1,89 @@
+package kit
+
+import (
+	"net/http"
+)
+
+// Router wraps a http.ServeMux, adapting HandlerFuncs with Handler.
+// Patterns follow the http.ServeMux syntax, including wildcards.
+//
+//	router := kit.NewRouter()
+//	router.Use(kit.WithRecovery)
+//	router.GET("/users/{id}", handleUserShow)
+//	router.POST("/users", handleUserCreate)
+type Router struct {
+	mux        *http.ServeMux
+	middleware []func(http.Handler) http.Handler
+	// handler is the mux wrapped in the middleware, rebuilt by Use.
+	handler http.Handler
+}
+
+// NewRouter returns a new Router.
+func NewRouter() *Router {
+	mux := http.NewServeMux()
+	return &Router{
+		mux:     mux,
+		handler: mux,
+	}
+}
+
+// Use adds middleware that run for all the requests, in the
+// order they are added. The middleware chain is built once here and
+// shared by all the requests, hence Use must be called before serving.
+func (r *Router) Use(mw ...func(http.Handler) http.Handler) {
+	r.middleware = append(r.middleware, mw...)
+	var handler http.Handler = r.mux
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	r.handler = handler
+}
+
+// Handle registers the given http.Handler for the given pattern.
+func (r *Router) Handle(pattern string, h http.Handler) {
+	r.mux.Handle(pattern, h)
+}
+
+// HandleFunc registers the given HandlerFunc for the given pattern,
+// matching all methods.
+func (r *Router) HandleFunc(pattern string, h HandlerFunc) {
+	r.mux.HandleFunc(pattern, Handler(h))
+}
+
+// GET registers the given HandlerFunc for GET (and HEAD) requests.
+func (r *Router) GET(pattern string, h HandlerFunc) {
+	r.method(http.MethodGet, pattern, h)
+}
+
+// POST registers the given HandlerFunc for POST requests.
+func (r *Router) POST(pattern string, h HandlerFunc) {
+	r.method(http.MethodPost, pattern, h)
+}
+
+// PUT registers the given HandlerFunc for PUT requests.
+func (r *Router) PUT(pattern string, h HandlerFunc) {
+	r.method(http.MethodPut, pattern, h)
+}
+
+// PATCH registers the given HandlerFunc for PATCH requests.
+func (r *Router) PATCH(pattern string, h HandlerFunc) {
+	r.method(http.MethodPatch, pattern, h)
+}
+
+// DELETE registers the given HandlerFunc for DELETE requests.
+func (r *Router) DELETE(pattern string, h HandlerFunc) {
+	r.method(http.MethodDelete, pattern, h)
+}
+
+// OPTIONS registers the given HandlerFunc for OPTIONS requests.
+func (r *Router) OPTIONS(pattern string, h HandlerFunc) {
+	r.method(http.MethodOptions, pattern, h)
+}
+
+func (r *Router) method(method, pattern string, h HandlerFunc) {
+	r.mux.HandleFunc(method+" "+pattern, Handler(h))
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
@@ -0,0 +1,68 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	calls := 0
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			next.ServeHTTP(w, r)
+		})
+	})
+	router.GET("/users/{id}", func(kit *Kit) error {
+		return kit.Text(http.StatusOK, "show "+kit.Request.PathValue("id"))
+	})
+	router.POST("/users/{id}", func(kit *Kit) error {
+		return kit.Text(http.StatusCreated, "update "+kit.Request.PathValue("id"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "show 1", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users/2", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "update 2", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/users/2", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	assert.Equal(t, 3, calls)
+}
+
+func TestRouterBuildsMiddlewareOnce(t *testing.T) {
+	router := NewRouter()
+	var built, order []string
+	for _, name := range []string{"first", "second"} {
+		router.Use(func(next http.Handler) http.Handler {
+			built = append(built, name)
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		})
+	}
+	router.GET("/", func(kit *Kit) error {
+		return kit.Text(http.StatusOK, "ok")
+	})
+	built = nil
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Empty(t, built)
+	assert.Equal(t, []string{"first", "second", "first", "second", "first", "second"}, order)
+}
@@ -0,0 +1,62 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []func(ctx context.Context) error
+)
+
+// OnShutdown registers a cleanup function run by Shutdown, like closing
+// database pools or flushing logs. Hooks run in the reverse order of
+// their registration, so resources are released in the reverse order of
+// their creation.
+//
+//	db := openDB()
+//	kit.OnShutdown(func(ctx context.Context) error { return db.Close() })
+func OnShutdown(fn func(ctx context.Context) error) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownHooks = append(shutdownHooks, fn)
+}
+
+// Shutdown runs the hooks registered with OnShutdown once, returning
+// their errors joined. A hook still running when the context is done is
+// reported with the context error, and the remaining hooks are skipped.
+//
+//	server.Shutdown(ctx)
+//	if err := kit.Shutdown(ctx); err != nil {
+//		log.Println(err)
+//	}
+func Shutdown(ctx context.Context) error {
+	shutdownMu.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	shutdownMu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("skipped %d remaining shutdown hooks: %w", i+1, err))
+			break
+		}
+		done := make(chan error, 1)
+		go func(hook func(ctx context.Context) error) {
+			done <- hook(ctx)
+		}(hooks[i])
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, ctx.Err()))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdown(t *testing.T) {
+	var order []int
+	for i := 0; i < 3; i++ {
+		OnShutdown(func(ctx context.Context) error {
+			order = append(order, i)
+			if i == 1 {
+				return errors.New("flush failed")
+			}
+			return nil
+		})
+	}
+	err := Shutdown(context.Background())
+	assert.Equal(t, []int{2, 1, 0}, order)
+	assert.ErrorContains(t, err, "flush failed")
+
+	// Hooks only run once.
+	assert.Nil(t, Shutdown(context.Background()))
+	assert.Len(t, order, 3)
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	var ran bool
+	OnShutdown(func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	release := make(chan struct{})
+	defer close(release)
+	OnShutdown(func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "shutdown hook 1")
+	assert.ErrorContains(t, err, "skipped 1 remaining shutdown hooks")
+	assert.False(t, ran)
+}
@@ -0,0 +1,47 @@
+package kit
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var signSecret = []byte("signing-secret")
+
+func TestSignURL(t *testing.T) {
+	signed, err := SignURL("https://example.com/download?v=1", map[string]string{
+		"file": "report.pdf",
+	}, time.Hour, signSecret)
+	assert.Nil(t, err)
+	assert.Contains(t, signed, "file=report.pdf")
+	assert.Contains(t, signed, "expires=")
+	assert.Contains(t, signed, "signature=")
+
+	r := httptest.NewRequest("GET", signed, nil)
+	assert.Nil(t, VerifySignedURL(r, signSecret))
+	assert.ErrorIs(t, VerifySignedURL(r, []byte("other-secret")), ErrSignatureInvalid)
+}
+
+func TestSignURLExpired(t *testing.T) {
+	signed, err := SignURL("https://example.com/download", nil, -time.Minute, signSecret)
+	assert.Nil(t, err)
+	r := httptest.NewRequest("GET", signed, nil)
+	assert.ErrorIs(t, VerifySignedURL(r, signSecret), ErrSignatureExpired)
+}
+
+func TestSignURLTampered(t *testing.T) {
+	signed, err := SignURL("https://example.com/download", map[string]string{
+		"file": "report.pdf",
+	}, time.Hour, signSecret)
+	assert.Nil(t, err)
+
+	tampered := strings.Replace(signed, "report.pdf", "secret.pdf", 1)
+	r := httptest.NewRequest("GET", tampered, nil)
+	assert.ErrorIs(t, VerifySignedURL(r, signSecret), ErrSignatureInvalid)
+
+	r = httptest.NewRequest("GET", "https://example.com/download?file=report.pdf", nil)
+	assert.ErrorIs(t, VerifySignedURL(r, signSecret), ErrSignatureInvalid)
+}
@@ -0,0 +1,107 @@
+package kit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SSEKeepAliveInterval is the interval at which SSE sends a keepalive
+// comment on an idle stream, so proxies do not drop the connection. Zero
+// disables the keepalives.
+var SSEKeepAliveInterval = 15 * time.Second
+
+// SSEEvent is a server-sent event.
+type SSEEvent struct {
+	// ID, if set, is sent as the event ID, which the browser sends back
+	// in the Last-Event-ID header when reconnecting.
+	ID string
+	// Event, if set, is the event type.
+	Event string
+	// Data is the payload of the event. Multiple lines are sent as
+	// multiple data fields.
+	Data string
+	// Retry, if set, tells the browser how long to wait before
+	// reconnecting.
+	Retry time.Duration
+}
+
+// SSE streams the events received on the given channel as server-sent
+// events, flushing the response after each one. Idle streams receive a
+// keepalive comment every SSEKeepAliveInterval. Streaming stops once the
+// channel is closed, with the context error when the request context is
+// done, or with the error of a failed write or flush, which usually means
+// the client is gone and the producer should stop.
+//
+//	events := make(chan kit.SSEEvent)
+//	go produce(ctx, events)
+//	return kit.SSE(events)
+func (kit *Kit) SSE(events <-chan SSEEvent) error {
+	if kit.aborted {
+		return nil
+	}
+	ctx := kit.Request.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	header := kit.Response.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	kit.Response.WriteHeader(http.StatusOK)
+	if err := kit.flush(); err != nil {
+		return err
+	}
+
+	var keepalive <-chan time.Time
+	if SSEKeepAliveInterval > 0 {
+		ticker := time.NewTicker(SSEKeepAliveInterval)
+		defer ticker.Stop()
+		keepalive = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-keepalive:
+			if err := kit.writeSSE(":keepalive\n\n"); err != nil {
+				return err
+			}
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := kit.writeSSE(event.String()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeSSE writes the given message and flushes it to the client.
+func (kit *Kit) writeSSE(msg string) error {
+	if _, err := kit.Response.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return kit.flush()
+}
+
+// String returns the event in the text/event-stream format.
+func (e SSEEvent) String() string {
+	var b strings.Builder
+	if len(e.ID) > 0 {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if len(e.Event) > 0 {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSE(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/events", nil))
+	events := make(chan SSEEvent, 2)
+	events <- SSEEvent{ID: "1", Event: "message", Data: "hello\nworld"}
+	events <- SSEEvent{Data: "bye", Retry: time.Second}
+	close(events)
+
+	assert.Nil(t, kit.SSE(events))
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 1\nevent: message\ndata: hello\ndata: world\n\n"+
+		"retry: 1000\ndata: bye\n\n", w.Body.String())
+}
+
+func TestSSEKeepAlive(t *testing.T) {
+	interval := SSEKeepAliveInterval
+	SSEKeepAliveInterval = 5 * time.Millisecond
+	defer func() { SSEKeepAliveInterval = interval }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	kit, w := newTestKit(httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+	err := kit.SSE(make(chan SSEEvent))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, w.Body.String(), ":keepalive\n\n")
+}
+
+// failingFlushRecorder fails every flush, like a client that is gone.
+type failingFlushRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingFlushRecorder) FlushError() error {
+	return errors.New("client gone")
+}
+
+func TestSSEFailedFlush(t *testing.T) {
+	w := failingFlushRecorder{httptest.NewRecorder()}
+	kit := &Kit{Response: w, Request: httptest.NewRequest("GET", "/events", nil)}
+	assert.EqualError(t, kit.SSE(make(chan SSEEvent)), "client gone")
+}
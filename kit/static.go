@@ -0,0 +1,137 @@
+package kit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StaticFS returns a handler serving the files of the given file system.
+// If the client accepts gzip and a precompressed variant (the file name
+// with a .gz suffix) exists, the variant is served instead with the
+// Content-Type of the original file.
+//
+//	router.Handle("/public/", http.StripPrefix("/public/", kit.StaticFS(public.AssetsFS)))
+func StaticFS(fsys fs.FS) http.Handler {
+	fileServer := http.FileServerFS(fsys)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) && serveGzipped(w, r, fsys) {
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// staticMaxAge is the max-age of the responses of FaviconHandler and
+// RobotsHandler.
+const staticMaxAge = 7 * 24 * time.Hour
+
+// FaviconHandler returns a handler serving the given favicon, so apps do
+// not need a static directory for it.
+//
+//	router.Handle("GET /favicon.ico", kit.FaviconHandler(favicon))
+func FaviconHandler(data []byte) http.HandlerFunc {
+	return staticHandler("image/x-icon", data)
+}
+
+// RobotsHandler returns a handler serving the given robots.txt.
+//
+//	router.Handle("GET /robots.txt", kit.RobotsHandler("User-agent: *\nDisallow: /admin"))
+func RobotsHandler(body string) http.HandlerFunc {
+	return staticHandler(withCharset("text/plain"), []byte(body))
+}
+
+func staticHandler(contentType string, data []byte) http.HandlerFunc {
+	modTime := time.Now()
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(staticMaxAge.Seconds())))
+		http.ServeContent(w, r, "", modTime, bytes.NewReader(data))
+	}
+}
+
+// File serves the file with the given name relative to root, e.g. a user
+// uploads directory. Names escaping root, like ../secret, result in
+// ErrForbidden and missing files in ErrNotFound. The Content-Type is
+// detected from the extension or the content of the file, and range and
+// conditional requests are supported like http.ServeFile.
+//
+//	return kit.File("./uploads", kit.Request.PathValue("name"))
+func (kit *Kit) File(root, name string) error {
+	if kit.aborted {
+		return nil
+	}
+	root = filepath.Clean(root)
+	fullPath := filepath.Join(root, filepath.FromSlash(name))
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ErrForbidden.Wrap(fmt.Errorf("path (%s) escapes the root directory", name))
+	}
+	file, err := os.Open(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound.Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return ErrNotFound.Wrap(fmt.Errorf("%s is a directory", name))
+	}
+	http.ServeContent(kit.Response, kit.Request, info.Name(), info.ModTime(), file)
+	return nil
+}
+
+// serveGzipped serves the precompressed variant of the requested file,
+// returning false if there is none.
+func serveGzipped(w http.ResponseWriter, r *http.Request, fsys fs.FS) bool {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if len(name) == 0 {
+		return false
+	}
+	file, err := fsys.Open(name + ".gz")
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		return false
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Encoding", "gzip")
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return true
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.TrimSpace(encoding) == "gzip" && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
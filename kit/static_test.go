@@ -0,0 +1,111 @@
+package kit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStaticFS(t *testing.T) fstest.MapFS {
+	css := []byte("body { color: red; }")
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	gw.Write(css)
+	assert.Nil(t, gw.Close())
+	return fstest.MapFS{
+		"styles.css":    {Data: css},
+		"styles.css.gz": {Data: buf.Bytes()},
+		"index.js":      {Data: []byte("console.log(1)")},
+	}
+}
+
+func TestStaticFSGzip(t *testing.T) {
+	h := StaticFS(newStaticFS(t))
+	r := httptest.NewRequest("GET", "/styles.css", nil)
+	r.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+	gr, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	b, err := io.ReadAll(gr)
+	assert.Nil(t, err)
+	assert.Equal(t, "body { color: red; }", string(b))
+}
+
+func TestStaticFSPlain(t *testing.T) {
+	h := StaticFS(newStaticFS(t))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/styles.css", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "body { color: red; }", w.Body.String())
+
+	// No precompressed variant available.
+	r := httptest.NewRequest("GET", "/index.js", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "console.log(1)", w.Body.String())
+}
+
+func TestFile(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "avatar.txt"), []byte("foo"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(filepath.Dir(root), "secret.txt"), []byte("secret"), 0o644))
+
+	serve := func(name string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		Handler(func(kit *Kit) error {
+			return kit.File(root, name)
+		}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w
+	}
+
+	w := serve("avatar.txt")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "foo", w.Body.String())
+
+	w = serve("missing.txt")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve("../secret.txt")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	w = serve(".")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFaviconHandler(t *testing.T) {
+	favicon := []byte{0x00, 0x00, 0x01, 0x00}
+	w := httptest.NewRecorder()
+	FaviconHandler(favicon).ServeHTTP(w, httptest.NewRequest("GET", "/favicon.ico", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/x-icon", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=604800", w.Header().Get("Cache-Control"))
+	assert.Equal(t, favicon, w.Body.Bytes())
+}
+
+func TestRobotsHandler(t *testing.T) {
+	robots := "User-agent: *\nDisallow: /admin\n"
+	w := httptest.NewRecorder()
+	RobotsHandler(robots).ServeHTTP(w, httptest.NewRequest("GET", "/robots.txt", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=604800", w.Header().Get("Cache-Control"))
+	assert.Equal(t, robots, w.Body.String())
+}
@@ -0,0 +1,201 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/a-h/templ"
+)
+
+// jsonStreamFlushInterval is the number of items after which JSONStream
+// flushes the response.
+const jsonStreamFlushInterval = 64
+
+// JSONStream writes all the items received on the given channel as a
+// single JSON array, without buffering the whole array in memory. The
+// array is closed once the channel is closed. Streaming is aborted when
+// the request context is done or an item fails to encode.
+func (kit *Kit) JSONStream(status int, items <-chan any) error {
+	if kit.aborted {
+		return nil
+	}
+	ctx := kit.Request.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	kit.Response.Header().Set("Content-Type", "application/json")
+	kit.Response.WriteHeader(status)
+	if _, err := kit.Response.Write([]byte("[")); err != nil {
+		return err
+	}
+	for n := 0; ; n++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				_, err := kit.Response.Write([]byte("]\n"))
+				return err
+			}
+			b, err := marshalJSON(item)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				b = append([]byte(","), b...)
+			}
+			if _, err := kit.Response.Write(b); err != nil {
+				return err
+			}
+			if (n+1)%jsonStreamFlushInterval == 0 {
+				if err := kit.flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// JSONCursor writes the items of all the pages returned by fetch as a
+// single JSON array, following the cursors until fetch returns an empty
+// next cursor. The first page is fetched with an empty cursor before
+// anything is written, so its error can still be handled by the error
+// handler. The response is flushed after every page. Streaming is aborted
+// when the request context is done, fetch fails or an item fails to
+// encode.
+//
+//	return kit.JSONCursor(http.StatusOK, func(cursor string) ([]any, string, error) {
+//		return db.ExportOrders(ctx, cursor, 500)
+//	})
+func (kit *Kit) JSONCursor(status int, fetch func(cursor string) (items []any, next string, err error)) error {
+	if kit.aborted {
+		return nil
+	}
+	ctx := kit.Request.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	items, next, err := fetch("")
+	if err != nil {
+		return err
+	}
+	kit.Response.Header().Set("Content-Type", "application/json")
+	kit.Response.WriteHeader(status)
+	if _, err := kit.Response.Write([]byte("[")); err != nil {
+		return err
+	}
+	for n := 0; ; {
+		for _, item := range items {
+			b, err := marshalJSON(item)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				b = append([]byte(","), b...)
+			}
+			if _, err := kit.Response.Write(b); err != nil {
+				return err
+			}
+			n++
+		}
+		if len(next) == 0 {
+			_, err := kit.Response.Write([]byte("]\n"))
+			return err
+		}
+		if err := kit.flush(); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if items, next, err = fetch(next); err != nil {
+			return err
+		}
+	}
+}
+
+// NDJSON writes all the items received on the given channel as newline
+// delimited JSON, flushing the response after each item. Streaming is
+// aborted when the request context is done or an item fails to encode.
+func (kit *Kit) NDJSON(status int, items <-chan any) error {
+	if kit.aborted {
+		return nil
+	}
+	ctx := kit.Request.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	kit.Response.Header().Set("Content-Type", "application/x-ndjson")
+	kit.Response.WriteHeader(status)
+	enc := json.NewEncoder(kit.Response)
+	enc.SetEscapeHTML(JSONEncoderConfig.EscapeHTML)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				return nil
+			}
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+			if err := kit.flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamComponents renders the components received on the given channel
+// as they arrive, flushing the response after each one. This allows
+// progressively building a page, e.g. sending a skeleton first and the
+// content once it is loaded. Streaming stops once the channel is closed,
+// with the context error when ctx or the request context is done, or
+// with the error of a component that failed to render.
+func (kit *Kit) StreamComponents(ctx context.Context, ch <-chan templ.Component) error {
+	if kit.aborted {
+		return nil
+	}
+	renderCtx, cancel := context.WithCancel(kit.RenderContext())
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	kit.Response.Header().Set("Content-Type", withCharset("text/html"))
+	kit.Response.WriteHeader(http.StatusOK)
+	for {
+		select {
+		case <-renderCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return renderCtx.Err()
+		case c, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			w := newRenderWriter(renderCtx, kit.Response)
+			if err := c.Render(renderCtx, w); err != nil {
+				return err
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// flush flushes the response if the underlying writer supports it.
+func (kit *Kit) flush() error {
+	err := http.NewResponseController(kit.Response).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+package kit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONStream(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	items := make(chan any, 3)
+	items <- map[string]int{"id": 1}
+	items <- map[string]int{"id": 2}
+	items <- map[string]int{"id": 3}
+	close(items)
+
+	assert.Nil(t, kit.JSONStream(http.StatusOK, items))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var result []map[string]int
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, []map[string]int{{"id": 1}, {"id": 2}, {"id": 3}}, result)
+}
+
+func TestJSONStreamEmpty(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	items := make(chan any)
+	close(items)
+	assert.Nil(t, kit.JSONStream(http.StatusOK, items))
+	assert.JSONEq(t, "[]", w.Body.String())
+}
+
+func TestJSONStreamContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	items := make(chan any)
+	go func() {
+		items <- 1
+		cancel()
+	}()
+	err := kit.JSONStream(http.StatusOK, items)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestJSONStreamEncodeError(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	items := make(chan any, 1)
+	items <- func() {}
+	assert.NotNil(t, kit.JSONStream(http.StatusOK, items))
+}
+
+func TestNDJSON(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/", nil))
+	items := make(chan any, 2)
+	items <- map[string]string{"event": "login"}
+	items <- map[string]string{"event": "logout"}
+	close(items)
+
+	assert.Nil(t, kit.NDJSON(http.StatusOK, items))
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed)
+
+	scanner := bufio.NewScanner(w.Body)
+	events := []string{}
+	for scanner.Scan() {
+		var item map[string]string
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &item))
+		events = append(events, item["event"])
+	}
+	assert.Equal(t, []string{"login", "logout"}, events)
+}
+
+func TestNDJSONContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	items := make(chan any)
+	go func() {
+		items <- 1
+		cancel()
+	}()
+	assert.ErrorIs(t, kit.NDJSON(http.StatusOK, items), context.Canceled)
+}
+
+// flushRecorder records the body at every flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []string
+}
+
+func (w *flushRecorder) Flush() {
+	w.flushes = append(w.flushes, w.Body.String())
+}
+
+func textComponent(s string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+func TestStreamComponents(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	kit := &Kit{Response: w, Request: httptest.NewRequest("GET", "/", nil)}
+	ch := make(chan templ.Component, 2)
+	ch <- textComponent("<div>skeleton</div>")
+	ch <- textComponent("<div>content</div>")
+	close(ch)
+
+	assert.Nil(t, kit.StreamComponents(context.Background(), ch))
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, []string{
+		"<div>skeleton</div>",
+		"<div>skeleton</div><div>content</div>",
+	}, w.flushes)
+}
+
+func TestStreamComponentsContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	ch := make(chan templ.Component)
+	go func() {
+		ch <- textComponent("foo")
+		cancel()
+	}()
+	assert.ErrorIs(t, kit.StreamComponents(ctx, ch), context.Canceled)
+}
+
+func TestStreamComponentsRenderError(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	ch := make(chan templ.Component, 1)
+	ch <- templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		return errors.New("render failed")
+	})
+	assert.EqualError(t, kit.StreamComponents(context.Background(), ch), "render failed")
+}
+
+func TestJSONCursor(t *testing.T) {
+	pages := map[string]struct {
+		items []any
+		next  string
+	}{
+		"":      {items: []any{map[string]int{"id": 1}, map[string]int{"id": 2}}, next: "page2"},
+		"page2": {items: []any{map[string]int{"id": 3}}},
+	}
+	var cursors []string
+	fetch := func(cursor string) ([]any, string, error) {
+		cursors = append(cursors, cursor)
+		page := pages[cursor]
+		return page.items, page.next, nil
+	}
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	kit := &Kit{Response: w, Request: httptest.NewRequest("GET", "/export", nil)}
+	assert.Nil(t, kit.JSONCursor(http.StatusOK, fetch))
+	assert.Equal(t, []string{"", "page2"}, cursors)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, []string{`[{"id":1},{"id":2}`}, w.flushes)
+
+	var items []map[string]int
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	assert.Equal(t, []map[string]int{{"id": 1}, {"id": 2}, {"id": 3}}, items)
+}
+
+func TestJSONCursorContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/export", nil).WithContext(ctx))
+	var fetches int
+	err := kit.JSONCursor(http.StatusOK, func(cursor string) ([]any, string, error) {
+		fetches++
+		cancel()
+		return []any{fetches}, "next", nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, fetches)
+}
+
+func TestJSONCursorFetchError(t *testing.T) {
+	kit, w := newTestKit(httptest.NewRequest("GET", "/export", nil))
+	err := kit.JSONCursor(http.StatusOK, func(cursor string) ([]any, string, error) {
+		return nil, "", errors.New("db down")
+	})
+	assert.EqualError(t, err, "db down")
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,177 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithTimeout runs the next handler with a request context that times out
+// after the given duration. When the deadline is exceeded before the
+// handler finished, the error handler is called with ErrGatewayTimeout.
+// When the client disconnects nothing is written, since nobody is left to
+// read the response.
+func WithTimeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			var (
+				tw     = newTimeoutWriter()
+				done   = make(chan struct{})
+				panics = make(chan any, 1)
+			)
+			child := r.WithContext(ctx)
+			// The handler keeps running after a timeout, hence it must not
+			// share the Kit of the middleware running before.
+			if _, ok := r.Context().Value(kitKey).(*Kit); ok {
+				child = kitFor(w, r).derive(tw, ctx).Request
+			}
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panics <- p
+					}
+				}()
+				next.ServeHTTP(tw, child)
+				close(done)
+			}()
+
+			select {
+			case p := <-panics:
+				panic(p)
+			case <-done:
+				tw.flushTo(w)
+			case <-ctx.Done():
+				tw.timeout()
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					// Use the original request, the context of the
+					// timed out request is already done.
+					errorHandler(kitFor(w, r), ErrGatewayTimeout)
+				}
+			}
+		})
+	}
+}
+
+// Timeout runs the handler with a request context that times out after
+// the given duration, allowing a single route to use a tighter deadline
+// than WithTimeout. A handler that overruns its deadline results in
+// ErrServiceUnavailable, its buffered response is discarded. The handler
+// runs on a copy of the Kit, its locals are only kept if it finished in
+// time.
+//
+//	router.GET("/report", handleReport.Timeout(2*time.Second))
+func (h HandlerFunc) Timeout(d time.Duration) HandlerFunc {
+	return func(kit *Kit) error {
+		ctx, cancel := context.WithTimeout(kit.Request.Context(), d)
+		defer cancel()
+
+		var (
+			tw     = newTimeoutWriter()
+			errs   = make(chan error, 1)
+			panics = make(chan any, 1)
+		)
+		inner := kit.derive(tw, ctx)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panics <- p
+				}
+			}()
+			errs <- h(inner)
+		}()
+
+		select {
+		case p := <-panics:
+			panic(p)
+		case err := <-errs:
+			// The handler is done, hence its locals can flow back.
+			kit.locals = inner.locals
+			kit.aborted = inner.aborted
+			if tw.written() {
+				tw.flushTo(kit.Response)
+			}
+			return err
+		case <-ctx.Done():
+			tw.timeout()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrServiceUnavailable
+			}
+			return nil
+		}
+	}
+}
+
+// timeoutWriter buffers the response of a handler running with a timeout,
+// so nothing is written to the client if the handler times out.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter() *timeoutWriter {
+	return &timeoutWriter{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.buf.Write(b)
+}
+
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.timedOut = true
+}
+
+func (tw *timeoutWriter) written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.wroteHeader
+}
+
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	for key, values := range tw.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(tw.status)
+	w.Write(tw.buf.Bytes())
+}
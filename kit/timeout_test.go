@@ -0,0 +1,182 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeout(t *testing.T) {
+	h := WithTimeout(time.Second)(Handler(func(kit *Kit) error {
+		return kit.Header("X-Foo", "bar").Text(http.StatusCreated, "fast")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "bar", w.Header().Get("X-Foo"))
+	assert.Equal(t, "fast", w.Body.String())
+}
+
+func TestWithTimeoutDeadlineExceeded(t *testing.T) {
+	h := WithTimeout(10 * time.Millisecond)(Handler(func(kit *Kit) error {
+		<-kit.Request.Context().Done()
+		return kit.Text(http.StatusOK, "slow")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NotContains(t, w.Body.String(), "slow")
+}
+
+func TestWithTimeoutClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := WithTimeout(time.Second)(Handler(func(kit *Kit) error {
+		cancel()
+		<-kit.Request.Context().Done()
+		return kit.Text(http.StatusOK, "slow")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assert.Empty(t, w.Header())
+	assert.Empty(t, w.Body.String())
+}
+
+func TestHandlerFuncTimeout(t *testing.T) {
+	var deadline time.Time
+	h := HandlerFunc(func(kit *Kit) error {
+		deadline, _ = kit.Request.Context().Deadline()
+		return kit.Text(http.StatusCreated, "fast")
+	}).Timeout(time.Second)
+	w := httptest.NewRecorder()
+	Handler(h).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "fast", w.Body.String())
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+}
+
+func TestHandlerFuncTimeoutShorterDeadline(t *testing.T) {
+	h := HandlerFunc(func(kit *Kit) error {
+		<-kit.Request.Context().Done()
+		return kit.Text(http.StatusOK, "slow")
+	}).Timeout(10 * time.Millisecond)
+	w := httptest.NewRecorder()
+	WithTimeout(time.Second)(Handler(h)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotContains(t, w.Body.String(), "slow")
+}
+
+func TestHandlerFuncTimeoutError(t *testing.T) {
+	h := HandlerFunc(func(kit *Kit) error {
+		return ErrNotFound
+	}).Timeout(time.Second)
+	w := httptest.NewRecorder()
+	Handler(h).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	deadline, ok := kit.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	remaining := kit.RemainingTime()
+	assert.True(t, remaining > 0 && remaining <= time.Second)
+	time.Sleep(10 * time.Millisecond)
+	assert.Less(t, kit.RemainingTime(), remaining)
+}
+
+func TestDeadlineNone(t *testing.T) {
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil))
+	_, ok := kit.Deadline()
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(math.MaxInt64), kit.RemainingTime())
+}
+
+func TestRemainingTimeExpired(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	kit, _ := newTestKit(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assert.Equal(t, time.Duration(0), kit.RemainingTime())
+}
+
+func TestWithTimeoutSharedKit(t *testing.T) {
+	logger, _ := newTestLogger()
+	config := LoggingConfig{
+		Logger:    logger,
+		AccessLog: &bytes.Buffer{},
+	}
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	h := WithLogging(config)(WithTimeout(10 * time.Millisecond)(Handler(func(kit *Kit) error {
+		defer close(finished)
+		<-release
+		kit.Set("user_id", 42)
+		return kit.Text(http.StatusOK, "slow")
+	})))
+	go func() {
+		// Keep the handler running after the timeout while the logging
+		// middleware reads its Kit.
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	<-finished
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestHandlerFuncTimeoutLocals(t *testing.T) {
+	var (
+		inner *Kit
+		nonce string
+	)
+	h := HandlerFunc(func(kit *Kit) error {
+		inner = kitFor(kit.Response, kit.Request)
+		nonce = kit.CSPNonce()
+		kit.Set("user_id", 42)
+		return nil
+	}).Timeout(time.Second)
+	outer := func(kit *Kit) error {
+		outerNonce := kit.CSPNonce()
+		if err := h(kit); err != nil {
+			return err
+		}
+		assert.Equal(t, outerNonce, nonce)
+		assert.Equal(t, 42, kit.Get("user_id"))
+		return nil
+	}
+	Handler(outer).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	// The handler receives the Kit stored in its request context.
+	assert.Equal(t, 42, inner.Get("user_id"))
+}
+
+func TestHandlerFuncTimeoutSetAfterDeadline(t *testing.T) {
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	h := HandlerFunc(func(kit *Kit) error {
+		defer close(finished)
+		<-release
+		kit.Set("late", true)
+		return nil
+	}).Timeout(10 * time.Millisecond)
+	var outer *Kit
+	Handler(func(kit *Kit) error {
+		outer = kit
+		err := h(kit)
+		close(release)
+		kit.Set("after", true)
+		return err
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-finished
+	assert.Nil(t, outer.Get("late"))
+	assert.Equal(t, true, outer.Get("after"))
+}
@@ -0,0 +1,29 @@
+package kit
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+)
+
+// GenerateToken returns a random token of nBytes bytes read from
+// crypto/rand, encoded as unpadded URL safe base64. It is suitable for
+// CSRF tokens, session identifiers and password reset links.
+func GenerateToken(nBytes int) (string, error) {
+	if nBytes <= 0 {
+		return "", fmt.Errorf("token size must be positive got %d", nBytes)
+	}
+	b := make([]byte, nBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ConstantTimeCompare reports whether a and b are equal, taking time
+// independent of their contents to prevent timing attacks when comparing
+// secret tokens. Only the length of the strings may leak.
+func ConstantTimeCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
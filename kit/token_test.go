@@ -0,0 +1,34 @@
+package kit
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := GenerateToken(32)
+		assert.Nil(t, err)
+		assert.Len(t, token, base64.RawURLEncoding.EncodedLen(32))
+		assert.False(t, seen[token])
+		seen[token] = true
+
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		assert.Nil(t, err)
+		assert.Len(t, b, 32)
+	}
+
+	_, err := GenerateToken(0)
+	assert.NotNil(t, err)
+}
+
+func TestConstantTimeCompare(t *testing.T) {
+	assert.True(t, ConstantTimeCompare("foo", "foo"))
+	assert.True(t, ConstantTimeCompare("", ""))
+	assert.False(t, ConstantTimeCompare("foo", "bar"))
+	assert.False(t, ConstantTimeCompare("foo", "foobar"))
+	assert.False(t, ConstantTimeCompare("foo", ""))
+}
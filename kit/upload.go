@@ -0,0 +1,214 @@
+package kit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// MultipartMaxMemory is the maximum number of bytes of a multipart
+	// form that will be kept in memory. The remainder is stored on disk
+	// in temporary files.
+	MultipartMaxMemory int64 = 32 << 20
+	// MultipartTempDir is the directory SaveUploadedFile stages uploads
+	// in before moving them to their destination. If empty the default
+	// temporary directory is used. Parts of multipart forms beyond
+	// MultipartMaxMemory are always stored in os.TempDir by the standard
+	// library, which is configured with TMPDIR (TMP on Windows).
+	MultipartTempDir string
+	// MaxUploadSize is the maximum number of bytes StreamUpload copies.
+	// Zero means no limit.
+	MaxUploadSize int64 = 1 << 30
+)
+
+// Errors returned by ValidateUpload.
+var (
+	ErrUploadTooLarge  = NewAPIError(http.StatusRequestEntityTooLarge, "upload is too large")
+	ErrUploadMIMEType  = NewAPIError(http.StatusUnsupportedMediaType, "upload has a disallowed content type")
+	ErrUploadExtension = NewAPIError(http.StatusUnsupportedMediaType, "upload has a disallowed file extension")
+)
+
+// UploadRules are the rules an uploaded file is validated against.
+// Zero values are not checked.
+type UploadRules struct {
+	// MaxSize is the maximum size of the file in bytes.
+	MaxSize int64
+	// AllowedMIME are the allowed content types, e.g. image/png. The
+	// content type is sniffed from the file content, hence the content
+	// type sent by the client is not trusted.
+	AllowedMIME []string
+	// AllowedExt are the allowed file extensions, e.g. .png.
+	AllowedExt []string
+}
+
+// ValidateUpload validates the given uploaded file against the given
+// rules, returning ErrUploadTooLarge, ErrUploadMIMEType or
+// ErrUploadExtension on violation.
+//
+//	err := kit.ValidateUpload(header, kit.UploadRules{
+//		MaxSize:     2 << 20,
+//		AllowedMIME: []string{"image/png", "image/jpeg"},
+//		AllowedExt:  []string{".png", ".jpg", ".jpeg"},
+//	})
+func ValidateUpload(fh *multipart.FileHeader, rules UploadRules) error {
+	if rules.MaxSize > 0 && fh.Size > rules.MaxSize {
+		return ErrUploadTooLarge.Wrap(fmt.Errorf("size %d exceeds the maximum of %d bytes", fh.Size, rules.MaxSize))
+	}
+	if len(rules.AllowedExt) > 0 {
+		ext := strings.ToLower(filepath.Ext(fh.Filename))
+		allowed := slices.ContainsFunc(rules.AllowedExt, func(allowed string) bool {
+			return strings.ToLower("."+strings.TrimPrefix(allowed, ".")) == ext
+		})
+		if !allowed {
+			return ErrUploadExtension.Wrap(fmt.Errorf("extension (%s) is not allowed", ext))
+		}
+	}
+	if len(rules.AllowedMIME) > 0 {
+		mimeType, err := sniffContentType(fh)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(rules.AllowedMIME, mimeType) {
+			return ErrUploadMIMEType.Wrap(fmt.Errorf("content type (%s) is not allowed", mimeType))
+		}
+	}
+	return nil
+}
+
+// sniffContentType detects the media type of the given file based on
+// its first 512 bytes.
+func sniffContentType(fh *multipart.FileHeader) (string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	b := make([]byte, 512)
+	n, err := io.ReadFull(file, b)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(b[:n]))
+	return mediaType, err
+}
+
+// FormFile returns the first file for the given multipart form field.
+// The form is parsed keeping at most MultipartMaxMemory bytes in memory.
+func (kit *Kit) FormFile(name string) (multipart.File, *multipart.FileHeader, error) {
+	if kit.Request.MultipartForm == nil {
+		if err := kit.parseMultipartForm(); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse multipart form: %w", err)
+		}
+	}
+	return kit.Request.FormFile(name)
+}
+
+// SaveUploadedFile saves the file of the given multipart form field to
+// dst. The file is first written to MultipartTempDir and then moved to
+// dst, so dst never contains a partially written upload.
+func (kit *Kit) SaveUploadedFile(name, dst string) error {
+	file, _, err := kit.FormFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tmp, err := os.CreateTemp(MultipartTempDir, "superkit-upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		// Renaming fails when the temp dir is on another device.
+		return copyFile(tmp.Name(), dst)
+	}
+	return nil
+}
+
+// parseMultipartForm parses the multipart form of the request, keeping at
+// most MultipartMaxMemory bytes in memory.
+func (kit *Kit) parseMultipartForm() error {
+	return kit.Request.ParseMultipartForm(MultipartMaxMemory)
+}
+
+// StreamUpload copies the file of the given multipart form field to dst
+// without buffering it in memory or on disk, returning the number of
+// bytes copied. The request body is consumed while reading, hence the
+// other form values are not available with FormValue afterwards.
+// ErrUploadTooLarge is returned as soon as the file exceeds
+// MaxUploadSize, in which case dst holds a partial upload.
+//
+//	n, err := kit.StreamUpload("video", objectWriter)
+func (kit *Kit) StreamUpload(field string, dst io.Writer) (int64, error) {
+	reader, err := kit.Request.MultipartReader()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read multipart form: %w", err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return 0, http.ErrMissingFile
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read multipart form: %w", err)
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		defer part.Close()
+		return copyUpload(dst, part, MaxUploadSize)
+	}
+}
+
+// copyUpload copies src to dst, failing with ErrUploadTooLarge once more
+// than max bytes have been read.
+func copyUpload(dst io.Writer, src io.Reader, max int64) (int64, error) {
+	if max <= 0 {
+		return io.Copy(dst, src)
+	}
+	n, err := io.Copy(dst, io.LimitReader(src, max))
+	if err != nil {
+		return n, err
+	}
+	if extra, _ := io.ReadFull(src, make([]byte, 1)); extra > 0 {
+		return n, ErrUploadTooLarge.Wrap(fmt.Errorf("upload exceeds the maximum of %d bytes", max))
+	}
+	return n, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,172 @@
+package kit
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newUploadRequest(t *testing.T, field, filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	part, err := mw.CreateFormFile(field, filename)
+	assert.Nil(t, err)
+	part.Write(content)
+	mw.WriteField("title", "my upload")
+	assert.Nil(t, mw.Close())
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestFormFile(t *testing.T) {
+	kit, _ := newTestKit(newUploadRequest(t, "avatar", "avatar.png", []byte("small")))
+	file, header, err := kit.FormFile("avatar")
+	assert.Nil(t, err)
+	defer file.Close()
+	assert.Equal(t, "avatar.png", header.Filename)
+	assert.Equal(t, int64(5), header.Size)
+	assert.Equal(t, "my upload", kit.FormValue("title"))
+
+	_, _, err = kit.FormFile("missing")
+	assert.NotNil(t, err)
+}
+
+func TestFormFileMaxMemory(t *testing.T) {
+	defer func(max int64) { MultipartMaxMemory = max }(MultipartMaxMemory)
+	MultipartMaxMemory = 1024
+
+	kit, _ := newTestKit(newUploadRequest(t, "video", "video.mp4", bytes.Repeat([]byte("a"), 4096)))
+	file, _, err := kit.FormFile("video")
+	assert.Nil(t, err)
+	defer file.Close()
+	// Files exceeding the max memory are stored on disk.
+	_, onDisk := file.(*os.File)
+	assert.True(t, onDisk)
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+	defer func(dir string) { MultipartTempDir = dir }(MultipartTempDir)
+	MultipartTempDir = t.TempDir()
+
+	content := bytes.Repeat([]byte("a"), 4096)
+	kit, _ := newTestKit(newUploadRequest(t, "video", "video.mp4", content))
+	dst := filepath.Join(t.TempDir(), "uploads", "video.mp4")
+	assert.Nil(t, kit.SaveUploadedFile("video", dst))
+
+	b, err := os.ReadFile(dst)
+	assert.Nil(t, err)
+	assert.Equal(t, content, b)
+
+	// The staged upload has been moved out of the temp dir.
+	entries, err := os.ReadDir(MultipartTempDir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSaveUploadedFileTempDir(t *testing.T) {
+	defer func(dir string) { MultipartTempDir = dir }(MultipartTempDir)
+	MultipartTempDir = filepath.Join(t.TempDir(), "does-not-exist")
+
+	kit, _ := newTestKit(newUploadRequest(t, "video", "video.mp4", []byte("a")))
+	err := kit.SaveUploadedFile("video", filepath.Join(t.TempDir(), "video.mp4"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// pngHeader is the signature of a PNG file.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func newFileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	kit, _ := newTestKit(newUploadRequest(t, "file", filename, content))
+	_, header, err := kit.FormFile("file")
+	assert.Nil(t, err)
+	return header
+}
+
+func TestValidateUpload(t *testing.T) {
+	rules := UploadRules{
+		MaxSize:     1024,
+		AllowedMIME: []string{"image/png"},
+		AllowedExt:  []string{".png", "jpg"},
+	}
+	assert.Nil(t, ValidateUpload(newFileHeader(t, "avatar.PNG", pngHeader), rules))
+}
+
+func TestValidateUploadTooLarge(t *testing.T) {
+	rules := UploadRules{MaxSize: 1024}
+	header := newFileHeader(t, "avatar.png", append(pngHeader, make([]byte, 2048)...))
+	assert.ErrorIs(t, ValidateUpload(header, rules), ErrUploadTooLarge)
+}
+
+func TestValidateUploadSpoofedContentType(t *testing.T) {
+	rules := UploadRules{AllowedMIME: []string{"image/png"}}
+	// The client claims to send a png, but the content is html.
+	header := newFileHeader(t, "avatar.png", []byte("<html><script>alert(1)</script></html>"))
+	header.Header.Set("Content-Type", "image/png")
+	assert.ErrorIs(t, ValidateUpload(header, rules), ErrUploadMIMEType)
+}
+
+func TestValidateUploadExtension(t *testing.T) {
+	rules := UploadRules{AllowedExt: []string{".png"}}
+	header := newFileHeader(t, "avatar.exe", pngHeader)
+	err := ValidateUpload(header, rules)
+	assert.ErrorIs(t, err, ErrUploadExtension)
+	assert.NotErrorIs(t, err, ErrUploadMIMEType)
+}
+
+func TestStreamUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 4096)
+	kit, _ := newTestKit(newUploadRequest(t, "video", "video.mp4", content))
+	var dst bytes.Buffer
+	n, err := kit.StreamUpload("video", &dst)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4096), n)
+	assert.Equal(t, content, dst.Bytes())
+
+	kit, _ = newTestKit(newUploadRequest(t, "video", "video.mp4", content))
+	_, err = kit.StreamUpload("missing", &dst)
+	assert.Equal(t, http.ErrMissingFile, err)
+}
+
+func TestStreamUploadTooLarge(t *testing.T) {
+	defer func(max int64) { MaxUploadSize = max }(MaxUploadSize)
+	MaxUploadSize = 1024
+
+	kit, _ := newTestKit(newUploadRequest(t, "video", "video.mp4", bytes.Repeat([]byte("a"), 4096)))
+	var dst bytes.Buffer
+	n, err := kit.StreamUpload("video", &dst)
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+	assert.Equal(t, int64(1024), n)
+
+	kit, _ = newTestKit(newUploadRequest(t, "video", "video.mp4", bytes.Repeat([]byte("a"), 1024)))
+	n, err = kit.StreamUpload("video", &dst)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024), n)
+}
+
+func TestFormFileTempDir(t *testing.T) {
+	defer func(max int64, dir string) {
+		MultipartMaxMemory, MultipartTempDir = max, dir
+	}(MultipartMaxMemory, MultipartTempDir)
+	MultipartMaxMemory = 1024
+	MultipartTempDir = t.TempDir()
+	tmpDir := os.Getenv("TMPDIR")
+
+	kit, _ := newTestKit(newUploadRequest(t, "video", "video.mp4", bytes.Repeat([]byte("a"), 4096)))
+	file, _, err := kit.FormFile("video")
+	assert.Nil(t, err)
+	defer file.Close()
+	// Spilled parts are left to the standard library, MultipartTempDir
+	// only applies to SaveUploadedFile.
+	f, onDisk := file.(*os.File)
+	assert.True(t, onDisk)
+	assert.Equal(t, filepath.Clean(os.TempDir()), filepath.Dir(f.Name()))
+	assert.Equal(t, tmpDir, os.Getenv("TMPDIR"))
+	assert.Nil(t, kit.Request.MultipartForm.RemoveAll())
+}
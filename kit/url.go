@@ -0,0 +1,93 @@
+package kit
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	baseURL       string
+	redirectHosts []string
+)
+
+// SafeRedirectFallback is the path SafeRedirect redirects to when the
+// given URL is not allowed.
+var SafeRedirectFallback = "/"
+
+// SetBaseURL overrides the scheme and host used by Kit.URL and
+// Kit.CurrentURL. It is useful for apps behind proxies rewriting the
+// path, in which case the base URL can include a path prefix.
+//
+//	kit.SetBaseURL("https://example.com/app")
+func SetBaseURL(url string) { baseURL = strings.TrimSuffix(url, "/") }
+
+// URL returns the absolute URL for the given path, based on the (proxy
+// aware) scheme and host of the current request or the base URL if set.
+//
+//	kit.URL("/login") // => https://example.com/login
+func (kit *Kit) URL(path string) string {
+	if len(path) > 0 && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(baseURL) > 0 {
+		return baseURL + path
+	}
+	return kit.Scheme() + "://" + kit.host() + path
+}
+
+// CurrentURL returns the absolute URL of the current request.
+func (kit *Kit) CurrentURL() string {
+	return kit.URL(kit.Request.URL.RequestURI())
+}
+
+// host returns the host the client requested. Behind a trusted proxy
+// the X-Forwarded-Host header is respected.
+func (kit *Kit) host() string {
+	if isTrustedProxy(kit.Request.RemoteAddr) {
+		if host := firstHeaderValue(kit.Request.Header.Get("X-Forwarded-Host")); len(host) > 0 {
+			return host
+		}
+	}
+	return kit.Request.Host
+}
+
+// SetRedirectHosts sets the external hosts SafeRedirect may redirect to,
+// in addition to the host of the current request.
+//
+//	kit.SetRedirectHosts("accounts.example.com")
+func SetRedirectHosts(hosts ...string) { redirectHosts = hosts }
+
+// SafeRedirect is like Redirect, but only redirects to relative URLs, the
+// host of the current request or the hosts set with SetRedirectHosts.
+// Other URLs, e.g. a ?next= parameter pointing to an external site, are
+// replaced by SafeRedirectFallback to prevent open redirects.
+//
+//	return kit.SafeRedirect(http.StatusSeeOther, kit.Request.URL.Query().Get("next"))
+func (kit *Kit) SafeRedirect(status int, url string) error {
+	if !kit.isSafeRedirect(url) {
+		url = SafeRedirectFallback
+	}
+	return kit.Redirect(status, url)
+}
+
+func (kit *Kit) isSafeRedirect(target string) bool {
+	// Browsers treat backslashes like slashes, so /\evil.com would be
+	// protocol relative.
+	if len(target) == 0 || strings.ContainsAny(target, "\\\x00\r\n\t") {
+		return false
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	if len(u.Scheme) == 0 && len(u.Host) == 0 {
+		// Relative URLs without a scheme must not be protocol relative.
+		return !strings.HasPrefix(target, "//") && len(u.Opaque) == 0
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	return host == strings.ToLower(kit.host()) || slices.Contains(redirectHosts, host)
+}
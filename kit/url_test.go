@@ -0,0 +1,68 @@
+package kit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURL(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/users?page=2", nil)
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "http://example.com/login", kit.URL("/login"))
+	assert.Equal(t, "http://example.com/login", kit.URL("login"))
+	assert.Equal(t, "http://example.com/users?page=2", kit.CurrentURL())
+}
+
+func TestURLProxied(t *testing.T) {
+	assert.Nil(t, SetTrustedProxies("10.0.0.1"))
+	defer SetTrustedProxies()
+
+	r := httptest.NewRequest("GET", "http://internal:3000/users?page=2", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "example.com")
+	kit, _ := newTestKit(r)
+	assert.Equal(t, "https://example.com/login", kit.URL("/login"))
+	assert.Equal(t, "https://example.com/users?page=2", kit.CurrentURL())
+}
+
+func TestURLBaseURL(t *testing.T) {
+	SetBaseURL("https://example.com/app/")
+	defer SetBaseURL("")
+
+	kit, _ := newTestKit(httptest.NewRequest("GET", "http://internal:3000/users", nil))
+	assert.Equal(t, "https://example.com/app/login", kit.URL("/login"))
+	assert.Equal(t, "https://example.com/app/users", kit.CurrentURL())
+}
+
+func serveSafeRedirect(target string) string {
+	kit, w := newTestKit(httptest.NewRequest("GET", "http://example.com/login", nil))
+	kit.SafeRedirect(http.StatusSeeOther, target)
+	return w.Header().Get("Location")
+}
+
+func TestSafeRedirect(t *testing.T) {
+	assert.Equal(t, "/dashboard?tab=1", serveSafeRedirect("/dashboard?tab=1"))
+	assert.Equal(t, "http://example.com/dashboard", serveSafeRedirect("http://example.com/dashboard"))
+}
+
+func TestSafeRedirectBlocked(t *testing.T) {
+	for _, target := range []string{
+		"https://evil.com/phish",
+		"//evil.com",
+		"/\\evil.com",
+		"javascript:alert(1)",
+		"",
+	} {
+		assert.Equal(t, "/", serveSafeRedirect(target), target)
+	}
+}
+
+func TestSetRedirectHosts(t *testing.T) {
+	SetRedirectHosts("accounts.example.com")
+	defer SetRedirectHosts()
+	assert.Equal(t, "https://accounts.example.com/login", serveSafeRedirect("https://accounts.example.com/login"))
+}
@@ -0,0 +1,177 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// contextWriter is an io.Writer that stops writing as soon as its
+// context is done, returning the context error.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw contextWriter) Write(b []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(b)
+}
+
+// renderBufferSize is the amount of rendered output Render buffers
+// before flushing it to the client.
+const renderBufferSize = 32 * 1024
+
+// renderWriter buffers the rendered output and flushes it to the client
+// once the buffer is full. Writing stops with the context error as soon
+// as the context is done.
+type renderWriter struct {
+	ctx context.Context
+	w   http.ResponseWriter
+	buf []byte
+}
+
+func newRenderWriter(ctx context.Context, w http.ResponseWriter) *renderWriter {
+	return &renderWriter{
+		ctx: ctx,
+		w:   w,
+		buf: make([]byte, 0, renderBufferSize),
+	}
+}
+
+func (rw *renderWriter) Write(b []byte) (int, error) {
+	if err := rw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	rw.buf = append(rw.buf, b...)
+	if len(rw.buf) >= renderBufferSize {
+		if err := rw.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes the buffered output to the client.
+func (rw *renderWriter) Flush() error {
+	if err := rw.ctx.Err(); err != nil {
+		return err
+	}
+	if len(rw.buf) == 0 {
+		return nil
+	}
+	if _, err := rw.w.Write(rw.buf); err != nil {
+		return err
+	}
+	rw.buf = rw.buf[:0]
+	err := http.NewResponseController(rw.w).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// headResponseWriter discards the body written in response to a HEAD
+// request, while keeping track of its length. The status is deferred
+// until finish is called so the Content-Length can still be set.
+type headResponseWriter struct {
+	http.ResponseWriter
+	status int
+	length int
+}
+
+func (hw *headResponseWriter) WriteHeader(status int) {
+	hw.status = status
+}
+
+func (hw *headResponseWriter) Write(b []byte) (int, error) {
+	hw.length += len(b)
+	return len(b), nil
+}
+
+func (hw *headResponseWriter) finish() {
+	if len(hw.Header().Get("Content-Length")) == 0 {
+		hw.Header().Set("Content-Length", strconv.Itoa(hw.length))
+	}
+	hw.ResponseWriter.WriteHeader(hw.status)
+}
+
+// failureWriter records whether writing the response failed, which means
+// the client is gone.
+type failureWriter struct {
+	http.ResponseWriter
+	failed bool
+}
+
+func (fw *failureWriter) Write(b []byte) (int, error) {
+	n, err := fw.ResponseWriter.Write(b)
+	if err != nil {
+		fw.failed = true
+	}
+	return n, err
+}
+
+func (fw *failureWriter) FlushError() error {
+	err := http.NewResponseController(fw.ResponseWriter).Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		fw.failed = true
+	}
+	return err
+}
+
+// Unwrap returns the underlying writer so http.ResponseController
+// can access it.
+func (fw *failureWriter) Unwrap() http.ResponseWriter {
+	return fw.ResponseWriter
+}
+
+// lengthWriter buffers writes up to the given threshold, in which case
+// the Content-Length is set on Close. Once the threshold is exceeded the
+// buffered and all subsequent writes are streamed to the client.
+type lengthWriter struct {
+	w         http.ResponseWriter
+	status    int
+	threshold int
+	buf       []byte
+	streaming bool
+}
+
+func newLengthWriter(w http.ResponseWriter, status int, threshold int) *lengthWriter {
+	return &lengthWriter{
+		w:         w,
+		status:    status,
+		threshold: threshold,
+	}
+}
+
+func (lw *lengthWriter) Write(b []byte) (int, error) {
+	if lw.streaming {
+		return lw.w.Write(b)
+	}
+	if len(lw.buf)+len(b) <= lw.threshold {
+		lw.buf = append(lw.buf, b...)
+		return len(b), nil
+	}
+	lw.streaming = true
+	lw.w.WriteHeader(lw.status)
+	if _, err := lw.w.Write(lw.buf); err != nil {
+		return 0, err
+	}
+	lw.buf = nil
+	return lw.w.Write(b)
+}
+
+// Close writes the buffered body, if any, with its Content-Length.
+func (lw *lengthWriter) Close() error {
+	if lw.streaming {
+		return nil
+	}
+	lw.w.Header().Set("Content-Length", strconv.Itoa(len(lw.buf)))
+	lw.w.WriteHeader(lw.status)
+	_, err := lw.w.Write(lw.buf)
+	return err
+}
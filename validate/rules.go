@@ -22,6 +22,10 @@ type RuleSet struct {
 	ErrorMessage string
 	MessageFunc  func(RuleSet) string
 	ValidateFunc func(RuleSet) bool
+
+	// validateErr, if set, is used instead of ValidateFunc and
+	// MessageFunc by the validators registered with RegisterValidator.
+	validateErr func(RuleSet) error
 }
 
 // Message overrides the default message of a RuleSet
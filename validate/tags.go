@@ -0,0 +1,193 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagRules holds the rules that can be used in `validate` struct tags,
+// each creating a RuleSet from the (optional) parameter of the tag.
+var tagRules = map[string]func(param string) (RuleSet, error){
+	"required": func(string) (RuleSet, error) {
+		return nonZero, nil
+	},
+	"email": func(string) (RuleSet, error) {
+		return Email, nil
+	},
+	"url": func(string) (RuleSet, error) {
+		return URL, nil
+	},
+	"containsUpper": func(string) (RuleSet, error) {
+		return ContainsUpper, nil
+	},
+	"containsDigit": func(string) (RuleSet, error) {
+		return ContainsDigit, nil
+	},
+	"containsSpecial": func(string) (RuleSet, error) {
+		return ContainsSpecial, nil
+	},
+	"min": func(param string) (RuleSet, error) {
+		return boundRule("min", param)
+	},
+	"max": func(param string) (RuleSet, error) {
+		return boundRule("max", param)
+	},
+}
+
+// RegisterValidator registers a custom rule that can be used in `validate`
+// struct tags under the given name, next to the built-in rules. The
+// validator receives the value of the field and the (optional) parameter
+// of the tag, a returned error fails validation with the error message.
+// Registering a built-in name overrides it. Validators should be
+// registered during initialization.
+//
+//	validate.RegisterValidator("slug", func(value any, param string) error {
+//		s, _ := value.(string)
+//		if !slugRegex.MatchString(s) {
+//			return errors.New("is not a valid slug")
+//		}
+//		return nil
+//	})
+func RegisterValidator(name string, fn func(value any, param string) error) {
+	tagRules[name] = func(param string) (RuleSet, error) {
+		return RuleSet{
+			Name:      name,
+			RuleValue: param,
+			validateErr: func(set RuleSet) error {
+				return fn(set.FieldValue, param)
+			},
+		}, nil
+	}
+}
+
+// boundRule returns the rule of the min and max tags, which compares
+// numbers by their value and strings, slices and maps by their length.
+// Nil pointers are not checked, use required for that.
+func boundRule(name, param string) (RuleSet, error) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("invalid %s parameter (%s)", name, param)
+	}
+	bound := "at least"
+	if name == "max" {
+		bound = "maximum"
+	}
+	return RuleSet{
+		Name:      name,
+		RuleValue: n,
+		ValidateFunc: func(set RuleSet) bool {
+			v := reflect.ValueOf(set.FieldValue)
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return true
+				}
+				v = v.Elem()
+			}
+			size, ok := fieldSize(v)
+			if !ok {
+				return false
+			}
+			if name == "max" {
+				return size <= n
+			}
+			return size >= n
+		},
+		MessageFunc: func(set RuleSet) string {
+			v := reflect.Indirect(reflect.ValueOf(set.FieldValue))
+			switch v.Kind() {
+			case reflect.String:
+				return fmt.Sprintf("should be %s %v characters long", bound, n)
+			case reflect.Slice, reflect.Array, reflect.Map:
+				return fmt.Sprintf("should have %s %v items", bound, n)
+			}
+			return fmt.Sprintf("should be %s %v", bound, n)
+		},
+	}, nil
+}
+
+// fieldSize returns the value of numbers and the length of strings,
+// slices and maps.
+func fieldSize(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// nonZero is the required rule for struct tags, which unlike Required
+// works for values of any type, e.g. slices for repeated parameters.
+var nonZero = RuleSet{
+	Name: "required",
+	MessageFunc: func(set RuleSet) string {
+		return "is a required field"
+	},
+	ValidateFunc: func(set RuleSet) bool {
+		v := reflect.ValueOf(set.FieldValue)
+		if !v.IsValid() {
+			return false
+		}
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+			return v.Len() > 0
+		}
+		return !v.IsZero()
+	},
+}
+
+// SchemaFromTags builds a Schema from the `validate` tags of the given
+// struct. Rules are separated by commas, parameters follow an equal sign.
+//
+//	type Filter struct {
+//		Query string   `validate:"required,min=3"`
+//		Tags  []string `validate:"required"`
+//	}
+func SchemaFromTags(v any) (Schema, error) {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct got %s", t.Kind())
+	}
+	schema := Schema{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("validate")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		rules := []RuleSet{}
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			newRule, ok := tagRules[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown validation rule (%s) on field %s", name, field.Name)
+			}
+			set, err := newRule(param)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			rules = append(rules, set)
+		}
+		schema[field.Name] = rules
+	}
+	return schema, nil
+}
+
+// Struct validates the given struct based on its `validate` tags. An
+// invalid tag will be reported under the "_error" key.
+func Struct(v any) (Errors, bool) {
+	schema, err := SchemaFromTags(v)
+	if err != nil {
+		return Errors{"_error": []string{err.Error()}}, false
+	}
+	return Validate(v, schema)
+}
@@ -82,9 +82,9 @@ func validate(data any, schema Schema, errors Errors) (Errors, bool) {
 			set.FieldValue = fieldValue
 			set.FieldName = fieldName
 			fieldName = string(unicode.ToLower([]rune(fieldName)[0])) + fieldName[1:]
-			if !set.ValidateFunc(set) {
+			valid, msg := validateRule(set)
+			if !valid {
 				ok = false
-				msg := set.MessageFunc(set)
 				if len(set.ErrorMessage) > 0 {
 					msg = set.ErrorMessage
 				}
@@ -98,6 +98,21 @@ func validate(data any, schema Schema, errors Errors) (Errors, bool) {
 	return errors, ok
 }
 
+// validateRule validates the field value of the given rule, returning
+// the message of a failed rule.
+func validateRule(set RuleSet) (bool, string) {
+	if set.validateErr != nil {
+		if err := set.validateErr(set); err != nil {
+			return false, err.Error()
+		}
+		return true, ""
+	}
+	if !set.ValidateFunc(set) {
+		return false, set.MessageFunc(set)
+	}
+	return true, ""
+}
+
 func getFieldValueByName(v any, name string) any {
 	val := reflect.ValueOf(v)
 	if val.Kind() == reflect.Ptr {
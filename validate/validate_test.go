@@ -119,3 +119,81 @@ func TestMergeSchemas(t *testing.T) {
 	c := Merge(a, b)
 	assert.Equal(t, expected, c)
 }
+
+func TestStruct(t *testing.T) {
+	type Filter struct {
+		Query string   `validate:"required,min=3"`
+		Email string   `validate:"email"`
+		Tags  []string `validate:"required"`
+		Page  int
+	}
+	errors, ok := Struct(Filter{Query: "foo", Email: "foo@bar.com", Tags: []string{"a"}})
+	assert.True(t, ok)
+	assert.Empty(t, errors)
+
+	errors, ok = Struct(Filter{Query: "fo", Email: "foo"})
+	assert.False(t, ok)
+	assert.Len(t, errors["query"], 1)
+	assert.Len(t, errors["email"], 1)
+	assert.Equal(t, []string{"is a required field"}, errors["tags"])
+}
+
+func TestStructInvalidTag(t *testing.T) {
+	type Foo struct {
+		Name string `validate:"foo"`
+		Age  string `validate:"min=x"`
+	}
+	errors, ok := Struct(Foo{})
+	assert.False(t, ok)
+	assert.Len(t, errors["_error"], 1)
+}
+
+func TestRegisterValidator(t *testing.T) {
+	RegisterValidator("prefix", func(value any, param string) error {
+		s, _ := value.(string)
+		if len(s) < len(param) || s[:len(param)] != param {
+			return fmt.Errorf("must start with %s", param)
+		}
+		return nil
+	})
+	defer delete(tagRules, "prefix")
+
+	type Order struct {
+		Number   string `validate:"required,prefix=ORD-"`
+		Quantity int    `validate:"min=1,max=100"`
+	}
+	errors, ok := Struct(Order{Number: "ORD-1", Quantity: 5})
+	assert.True(t, ok)
+	assert.Empty(t, errors)
+
+	errors, ok = Struct(Order{Number: "INV-1", Quantity: 500})
+	assert.False(t, ok)
+	assert.Equal(t, []string{"must start with ORD-"}, errors["number"])
+	assert.Equal(t, []string{"should be maximum 100"}, errors["quantity"])
+}
+
+func TestStructNumericBounds(t *testing.T) {
+	type Order struct {
+		Quantity int      `validate:"min=1,max=10"`
+		Price    float64  `validate:"min=0.5"`
+		Discount *int     `validate:"max=50"`
+		Items    []string `validate:"max=2"`
+		Note     string   `validate:"max=5"`
+	}
+	errors, ok := Struct(Order{Quantity: 5, Price: 0.5, Items: []string{"a"}, Note: "hi"})
+	assert.True(t, ok)
+	assert.Empty(t, errors)
+
+	discount := 60
+	errors, ok = Struct(Order{Quantity: 11, Price: 0.25, Discount: &discount, Items: []string{"a", "b", "c"}, Note: "too long"})
+	assert.False(t, ok)
+	assert.Equal(t, []string{"should be maximum 10"}, errors["quantity"])
+	assert.Equal(t, []string{"should be at least 0.5"}, errors["price"])
+	assert.Equal(t, []string{"should be maximum 50"}, errors["discount"])
+	assert.Equal(t, []string{"should have maximum 2 items"}, errors["items"])
+	assert.Equal(t, []string{"should be maximum 5 characters long"}, errors["note"])
+
+	errors, ok = Struct(Order{Quantity: 0, Price: 1})
+	assert.False(t, ok)
+	assert.Equal(t, []string{"should be at least 1"}, errors["quantity"])
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/a-h/templ"
 	"github.com/anthdm/superkit/kit"
 	"github.com/anthdm/superkit/kit/middleware"
 )
@@ -32,7 +34,11 @@ func getContextValue[T any](ctx context.Context, key interface{}, defaultValue T
 //
 //	view.Auth(ctx)
 func Auth(ctx context.Context) kit.Auth {
-	return getContextValue(ctx, kit.AuthKey{}, kit.DefaultAuth{})
+	auth, ok := kit.AuthFromContext(ctx)
+	if !ok {
+		return kit.DefaultAuth{}
+	}
+	return auth
 }
 
 // URL is a view helper that returns the current URL.
@@ -42,3 +48,40 @@ func Auth(ctx context.Context) kit.Auth {
 func URL(ctx context.Context) *url.URL {
 	return getContextValue(ctx, middleware.RequestURLKey{}, &url.URL{})
 }
+
+// Flashes is a view helper that returns the flash messages of the
+// current request.
+//
+//	view.Flashes(ctx)
+func Flashes(ctx context.Context) []string {
+	if flashes := kit.FlashesFromContext(ctx); flashes != nil {
+		return flashes
+	}
+	return []string{}
+}
+
+// CSPNonce is a view helper that returns the Content Security Policy
+// nonce of the current request, see kit.Kit.CSPNonce.
+//
+//	<script nonce={ view.CSPNonce(ctx) }>...</script>
+func CSPNonce(ctx context.Context) string {
+	return templ.GetNonce(ctx)
+}
+
+// FormatNumber is a view helper that formats the number according to the
+// locale of the current request, see kit.Kit.FormatNumber.
+//
+//	{ view.FormatNumber(ctx, order.Total) }
+func FormatNumber(ctx context.Context, n float64) string {
+	locale, _ := kit.LocaleFromContext(ctx)
+	return kit.FormatNumber(locale, n)
+}
+
+// FormatDate is a view helper that formats the date according to the
+// locale of the current request, see kit.Kit.FormatDate.
+//
+//	{ view.FormatDate(ctx, order.CreatedAt) }
+func FormatDate(ctx context.Context, t time.Time) string {
+	locale, _ := kit.LocaleFromContext(ctx)
+	return kit.FormatDate(locale, t)
+}